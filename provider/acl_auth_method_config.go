@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// The config of an auth method is given as a map of strings. The values of
+// the keys holding lists or maps are JSON-encoded, and the values of the
+// keys of the known auth method types are converted to the type Consul
+// expects for them.
+const (
+	aclAuthMethodConfigString   = "string"
+	aclAuthMethodConfigBool     = "bool"
+	aclAuthMethodConfigDuration = "duration"
+	aclAuthMethodConfigList     = "list"
+	aclAuthMethodConfigMap      = "map"
+)
+
+// aclAuthMethodConfigKey describes a key of the config of an auth method.
+type aclAuthMethodConfigKey struct {
+	Kind     string
+	Required bool
+}
+
+// aclAuthMethodJWTConfigKeys are the keys shared by the config of the jwt
+// and oidc auth methods.
+var aclAuthMethodJWTConfigKeys = map[string]aclAuthMethodConfigKey{
+	"BoundAudiences":    {Kind: aclAuthMethodConfigList},
+	"BoundIssuer":       {Kind: aclAuthMethodConfigString},
+	"ClaimMappings":     {Kind: aclAuthMethodConfigMap},
+	"ListClaimMappings": {Kind: aclAuthMethodConfigMap},
+	"JWTSupportedAlgs":  {Kind: aclAuthMethodConfigList},
+}
+
+// aclAuthMethodConfigKeys are the keys of the config of the known auth
+// method types. The config of other types is passed through as is.
+var aclAuthMethodConfigKeys = map[string]map[string]aclAuthMethodConfigKey{
+	"kubernetes": {
+		"Host":              {Kind: aclAuthMethodConfigString, Required: true},
+		"CACert":            {Kind: aclAuthMethodConfigString, Required: true},
+		"ServiceAccountJWT": {Kind: aclAuthMethodConfigString, Required: true},
+
+		// Namespaces are a Consul Enterprise feature.
+		"MapNamespaces":            {Kind: aclAuthMethodConfigBool},
+		"ConsulNamespacePrefix":    {Kind: aclAuthMethodConfigString},
+		"ConsulNamespaceOverrides": {Kind: aclAuthMethodConfigMap},
+	},
+	"jwt": withACLAuthMethodJWTConfigKeys(map[string]aclAuthMethodConfigKey{
+		"JWTValidationPubKeys": {Kind: aclAuthMethodConfigList},
+		"JWKSURL":              {Kind: aclAuthMethodConfigString},
+		"JWKSCACert":           {Kind: aclAuthMethodConfigString},
+		"OIDCDiscoveryURL":     {Kind: aclAuthMethodConfigString},
+		"OIDCDiscoveryCACert":  {Kind: aclAuthMethodConfigString},
+		"ExpirationLeeway":     {Kind: aclAuthMethodConfigDuration},
+		"NotBeforeLeeway":      {Kind: aclAuthMethodConfigDuration},
+		"ClockSkewLeeway":      {Kind: aclAuthMethodConfigDuration},
+	}),
+	"oidc": withACLAuthMethodJWTConfigKeys(map[string]aclAuthMethodConfigKey{
+		"OIDCDiscoveryURL":    {Kind: aclAuthMethodConfigString, Required: true},
+		"OIDCDiscoveryCACert": {Kind: aclAuthMethodConfigString},
+		"OIDCClientID":        {Kind: aclAuthMethodConfigString, Required: true},
+		"OIDCClientSecret":    {Kind: aclAuthMethodConfigString, Required: true},
+		"OIDCScopes":          {Kind: aclAuthMethodConfigList},
+		"AllowedRedirectURIs": {Kind: aclAuthMethodConfigList, Required: true},
+		"VerboseOIDCLogging":  {Kind: aclAuthMethodConfigBool},
+	}),
+}
+
+func withACLAuthMethodJWTConfigKeys(keys map[string]aclAuthMethodConfigKey) map[string]aclAuthMethodConfigKey {
+	for k, v := range aclAuthMethodJWTConfigKeys {
+		keys[k] = v
+	}
+	return keys
+}
+
+// expandACLAuthMethodConfig returns the config of an auth method of type
+// methodType given as a map of strings, checking it against the keys of the
+// known types.
+func expandACLAuthMethodConfig(methodType string, raw map[string]interface{}) (map[string]interface{}, error) {
+	config := make(map[string]interface{}, len(raw))
+	keys, known := aclAuthMethodConfigKeys[methodType]
+	if !known {
+		for k, v := range raw {
+			config[k] = v
+		}
+		return config, nil
+	}
+
+	for _, k := range sortedKeys(raw) {
+		v := raw[k].(string)
+		key, ok := keys[k]
+		if !ok {
+			return nil, fmt.Errorf("Unsupported config key '%s' for the %s auth method", k, methodType)
+		}
+
+		switch key.Kind {
+		case aclAuthMethodConfigString:
+			config[k] = v
+		case aclAuthMethodConfigBool:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid config key '%s' for the %s auth method: %q is not a boolean", k, methodType, v)
+			}
+			config[k] = b
+		case aclAuthMethodConfigDuration:
+			if _, err := time.ParseDuration(v); err != nil {
+				return nil, fmt.Errorf("Invalid config key '%s' for the %s auth method: %v", k, methodType, err)
+			}
+			config[k] = v
+		case aclAuthMethodConfigList:
+			var l []interface{}
+			if err := json.Unmarshal([]byte(v), &l); err != nil {
+				return nil, fmt.Errorf("Invalid config key '%s' for the %s auth method: expected a JSON-encoded list: %v", k, methodType, err)
+			}
+			config[k] = l
+		case aclAuthMethodConfigMap:
+			var m map[string]interface{}
+			if err := json.Unmarshal([]byte(v), &m); err != nil {
+				return nil, fmt.Errorf("Invalid config key '%s' for the %s auth method: expected a JSON-encoded object: %v", k, methodType, err)
+			}
+			config[k] = m
+		}
+	}
+
+	var missing []string
+	for k, key := range keys {
+		if _, ok := raw[k]; key.Required && !ok {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("Missing config keys for the %s auth method: %s", methodType, strings.Join(missing, ", "))
+	}
+
+	// JWTs are validated with exactly one of these sources of keys.
+	if methodType == "jwt" {
+		var sources []string
+		for _, k := range []string{"JWTValidationPubKeys", "JWKSURL", "OIDCDiscoveryURL"} {
+			if _, ok := raw[k]; ok {
+				sources = append(sources, k)
+			}
+		}
+		if len(sources) != 1 {
+			return nil, fmt.Errorf("Exactly one of JWTValidationPubKeys, JWKSURL and OIDCDiscoveryURL must be set for the jwt auth method")
+		}
+	}
+
+	return config, nil
+}
+
+// flattenACLAuthMethodConfig returns the config of an auth method as a map
+// of strings, JSON-encoding its lists and maps.
+func flattenACLAuthMethodConfig(config map[string]interface{}) (map[string]interface{}, error) {
+	raw := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		switch v := v.(type) {
+		case string:
+			raw[k] = v
+		case bool:
+			raw[k] = strconv.FormatBool(v)
+		case float64:
+			raw[k] = strconv.FormatFloat(v, 'g', -1, 64)
+		case nil:
+			raw[k] = ""
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to encode config key '%s': %v", k, err)
+			}
+			raw[k] = string(b)
+		}
+	}
+	return raw, nil
+}
+
+// suppressEquivalentJSON suppresses the differences between values that
+// are the same JSON document, such as lists and maps of the config of an
+// auth method written with a different layout.
+func suppressEquivalentJSON(k, old, new string, d *schema.ResourceData) bool {
+	var o, n interface{}
+	if err := json.Unmarshal([]byte(old), &o); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &n); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(o, n)
+}
@@ -6,12 +6,17 @@ package provider
 import (
 	"fmt"
 	"log"
+	"regexp"
+	"strconv"
+	"strings"
 
 	consulapi "github.com/hashicorp/consul/api"
 )
 
 // aclClient is a wrapper around the upstream Consul client that is
-// specialized for Terraform's manipulations of the key/value store.
+// specialized for Terraform's manipulations of ACLs. Read, Create, Update
+// and Delete manage legacy ACL tokens, while the other methods manage the
+// tokens, policies and roles of the ACL system introduced in Consul 1.4.
 type aclClient struct {
 	client *consulapi.ACL
 	qOpts  *consulapi.QueryOptions
@@ -75,3 +80,354 @@ func (c *aclClient) Update(acl *consulapi.ACLEntry) error {
 	}
 	return nil
 }
+
+// isACLNotFound returns whether err is the error returned by Consul when
+// reading a token or a policy that does not exist.
+func isACLNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "ACL not found")
+}
+
+// ReadPolicy returns the policy with the given ID, or nil if it does not
+// exist.
+func (c *aclClient) ReadPolicy(id string) (*consulapi.ACLPolicy, error) {
+	log.Printf(
+		"[DEBUG] Reading ACL policy '%s' in %s",
+		id, c.qOpts.Datacenter,
+	)
+	policy, _, err := c.client.PolicyRead(id, c.qOpts)
+	if isACLNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read Consul ACL policy '%s': %s", id, err)
+	}
+	return policy, nil
+}
+
+// CreatePolicy creates policy and sets its ID.
+func (c *aclClient) CreatePolicy(policy *consulapi.ACLPolicy) error {
+	log.Printf(
+		"[DEBUG] Creating ACL policy '%s' in %s",
+		policy.Name, c.wOpts.Datacenter,
+	)
+	created, _, err := c.client.PolicyCreate(policy, c.wOpts)
+	if err != nil {
+		return fmt.Errorf("Failed to write Consul ACL policy '%s': %s", policy.Name, err)
+	}
+	policy.ID = created.ID
+	return nil
+}
+
+func (c *aclClient) UpdatePolicy(policy *consulapi.ACLPolicy) error {
+	log.Printf(
+		"[DEBUG] Setting ACL policy '%s' in %s",
+		policy.Name, c.wOpts.Datacenter,
+	)
+	if _, _, err := c.client.PolicyUpdate(policy, c.wOpts); err != nil {
+		return fmt.Errorf("Failed to write Consul ACL policy '%s': %s", policy.Name, err)
+	}
+	return nil
+}
+
+func (c *aclClient) DeletePolicy(id string) error {
+	log.Printf(
+		"[DEBUG] Deleting ACL policy '%s' in %s",
+		id, c.wOpts.Datacenter,
+	)
+	if _, err := c.client.PolicyDelete(id, c.wOpts); err != nil {
+		return fmt.Errorf("Failed to delete Consul ACL policy '%s': %s", id, err)
+	}
+	return nil
+}
+
+// ReadToken returns the token with the given accessor ID, including its
+// secret ID, or nil if it does not exist.
+func (c *aclClient) ReadToken(accessorID string) (*consulapi.ACLToken, error) {
+	log.Printf(
+		"[DEBUG] Reading ACL token '%s' in %s",
+		accessorID, c.qOpts.Datacenter,
+	)
+	token, _, err := c.client.TokenRead(accessorID, c.qOpts)
+	if isACLNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read Consul ACL token '%s': %s", accessorID, err)
+	}
+	return token, nil
+}
+
+// CreateToken creates token and sets its accessor and secret IDs, which
+// are generated by Consul unless they are set.
+func (c *aclClient) CreateToken(token *consulapi.ACLToken) error {
+	log.Printf(
+		"[DEBUG] Creating ACL token '%s' in %s",
+		token.Description, c.wOpts.Datacenter,
+	)
+	created, _, err := c.client.TokenCreate(token, c.wOpts)
+	if err != nil {
+		return fmt.Errorf("Failed to write Consul ACL token '%s': %s", token.Description, err)
+	}
+	token.AccessorID = created.AccessorID
+	token.SecretID = created.SecretID
+	return nil
+}
+
+func (c *aclClient) UpdateToken(token *consulapi.ACLToken) error {
+	log.Printf(
+		"[DEBUG] Setting ACL token '%s' in %s",
+		token.AccessorID, c.wOpts.Datacenter,
+	)
+	if _, _, err := c.client.TokenUpdate(token, c.wOpts); err != nil {
+		return fmt.Errorf("Failed to write Consul ACL token '%s': %s", token.AccessorID, err)
+	}
+	return nil
+}
+
+func (c *aclClient) DeleteToken(accessorID string) error {
+	log.Printf(
+		"[DEBUG] Deleting ACL token '%s' in %s",
+		accessorID, c.wOpts.Datacenter,
+	)
+	if _, err := c.client.TokenDelete(accessorID, c.wOpts); err != nil {
+		return fmt.Errorf("Failed to delete Consul ACL token '%s': %s", accessorID, err)
+	}
+	return nil
+}
+
+// ReadRole returns the role with the given ID, or nil if it does not exist.
+func (c *aclClient) ReadRole(id string) (*consulapi.ACLRole, error) {
+	log.Printf(
+		"[DEBUG] Reading ACL role '%s' in %s",
+		id, c.qOpts.Datacenter,
+	)
+	role, _, err := c.client.RoleRead(id, c.qOpts)
+	if isACLNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read Consul ACL role '%s': %s", id, err)
+	}
+	return role, nil
+}
+
+// CreateRole creates role and sets its ID.
+func (c *aclClient) CreateRole(role *consulapi.ACLRole) error {
+	log.Printf(
+		"[DEBUG] Creating ACL role '%s' in %s",
+		role.Name, c.wOpts.Datacenter,
+	)
+	created, _, err := c.client.RoleCreate(role, c.wOpts)
+	if err != nil {
+		return fmt.Errorf("Failed to write Consul ACL role '%s': %s", role.Name, err)
+	}
+	role.ID = created.ID
+	return nil
+}
+
+func (c *aclClient) UpdateRole(role *consulapi.ACLRole) error {
+	log.Printf(
+		"[DEBUG] Setting ACL role '%s' in %s",
+		role.Name, c.wOpts.Datacenter,
+	)
+	if _, _, err := c.client.RoleUpdate(role, c.wOpts); err != nil {
+		return fmt.Errorf("Failed to write Consul ACL role '%s': %s", role.Name, err)
+	}
+	return nil
+}
+
+func (c *aclClient) DeleteRole(id string) error {
+	log.Printf(
+		"[DEBUG] Deleting ACL role '%s' in %s",
+		id, c.wOpts.Datacenter,
+	)
+	if _, err := c.client.RoleDelete(id, c.wOpts); err != nil {
+		return fmt.Errorf("Failed to delete Consul ACL role '%s': %s", id, err)
+	}
+	return nil
+}
+
+// aclBootstrapNotAllowedRe matches the error of Consul when bootstrapping an
+// ACL system that is already bootstrapped.
+var aclBootstrapNotAllowedRe = regexp.MustCompile(`ACL bootstrap no longer allowed \(reset index: (\d+)\)`)
+
+// aclBootstrapNotAllowedError is returned by Bootstrap when the ACL system
+// has already been bootstrapped. ResetIndex is the index to write to the
+// bootstrap reset file of the leader to allow a new bootstrap.
+type aclBootstrapNotAllowedError struct {
+	ResetIndex uint64
+}
+
+func (e *aclBootstrapNotAllowedError) Error() string {
+	return fmt.Sprintf("ACL bootstrap no longer allowed (reset index: %d)", e.ResetIndex)
+}
+
+// Bootstrap creates the initial management token of the ACL system. It
+// returns an *aclBootstrapNotAllowedError if the ACL system has already
+// been bootstrapped.
+func (c *aclClient) Bootstrap() (*consulapi.ACLToken, error) {
+	log.Printf("[DEBUG] Bootstrapping ACL system")
+	token, _, err := c.client.Bootstrap()
+	if err != nil {
+		if m := aclBootstrapNotAllowedRe.FindStringSubmatch(err.Error()); m != nil {
+			resetIndex, _ := strconv.ParseUint(m[1], 10, 64)
+			return nil, &aclBootstrapNotAllowedError{ResetIndex: resetIndex}
+		}
+		return nil, fmt.Errorf("Failed to bootstrap Consul ACL system: %s", err)
+	}
+	return token, nil
+}
+
+// ReadAuthMethod returns the auth method with the given name, or nil if it
+// does not exist.
+func (c *aclClient) ReadAuthMethod(name string) (*consulapi.ACLAuthMethod, error) {
+	log.Printf(
+		"[DEBUG] Reading ACL auth method '%s' in %s",
+		name, c.qOpts.Datacenter,
+	)
+	method, _, err := c.client.AuthMethodRead(name, c.qOpts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read Consul ACL auth method '%s': %s", name, err)
+	}
+	return method, nil
+}
+
+func (c *aclClient) CreateAuthMethod(method *consulapi.ACLAuthMethod) error {
+	log.Printf(
+		"[DEBUG] Creating ACL auth method '%s' in %s",
+		method.Name, c.wOpts.Datacenter,
+	)
+	if _, _, err := c.client.AuthMethodCreate(method, c.wOpts); err != nil {
+		return fmt.Errorf("Failed to write Consul ACL auth method '%s': %s", method.Name, err)
+	}
+	return nil
+}
+
+func (c *aclClient) UpdateAuthMethod(method *consulapi.ACLAuthMethod) error {
+	log.Printf(
+		"[DEBUG] Setting ACL auth method '%s' in %s",
+		method.Name, c.wOpts.Datacenter,
+	)
+	if _, _, err := c.client.AuthMethodUpdate(method, c.wOpts); err != nil {
+		return fmt.Errorf("Failed to write Consul ACL auth method '%s': %s", method.Name, err)
+	}
+	return nil
+}
+
+func (c *aclClient) DeleteAuthMethod(name string) error {
+	log.Printf(
+		"[DEBUG] Deleting ACL auth method '%s' in %s",
+		name, c.wOpts.Datacenter,
+	)
+	if _, err := c.client.AuthMethodDelete(name, c.wOpts); err != nil {
+		return fmt.Errorf("Failed to delete Consul ACL auth method '%s': %s", name, err)
+	}
+	return nil
+}
+
+// ReadBindingRule returns the binding rule with the given ID, or nil if it
+// does not exist.
+func (c *aclClient) ReadBindingRule(id string) (*consulapi.ACLBindingRule, error) {
+	log.Printf(
+		"[DEBUG] Reading ACL binding rule '%s' in %s",
+		id, c.qOpts.Datacenter,
+	)
+	rule, _, err := c.client.BindingRuleRead(id, c.qOpts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read Consul ACL binding rule '%s': %s", id, err)
+	}
+	return rule, nil
+}
+
+// CreateBindingRule creates rule and sets its ID.
+func (c *aclClient) CreateBindingRule(rule *consulapi.ACLBindingRule) error {
+	log.Printf(
+		"[DEBUG] Creating ACL binding rule for auth method '%s' in %s",
+		rule.AuthMethod, c.wOpts.Datacenter,
+	)
+	created, _, err := c.client.BindingRuleCreate(rule, c.wOpts)
+	if err != nil {
+		return fmt.Errorf("Failed to write Consul ACL binding rule for auth method '%s': %s", rule.AuthMethod, err)
+	}
+	rule.ID = created.ID
+	return nil
+}
+
+func (c *aclClient) UpdateBindingRule(rule *consulapi.ACLBindingRule) error {
+	log.Printf(
+		"[DEBUG] Setting ACL binding rule '%s' in %s",
+		rule.ID, c.wOpts.Datacenter,
+	)
+	if _, _, err := c.client.BindingRuleUpdate(rule, c.wOpts); err != nil {
+		return fmt.Errorf("Failed to write Consul ACL binding rule '%s': %s", rule.ID, err)
+	}
+	return nil
+}
+
+func (c *aclClient) DeleteBindingRule(id string) error {
+	log.Printf(
+		"[DEBUG] Deleting ACL binding rule '%s' in %s",
+		id, c.wOpts.Datacenter,
+	)
+	if _, err := c.client.BindingRuleDelete(id, c.wOpts); err != nil {
+		return fmt.Errorf("Failed to delete Consul ACL binding rule '%s': %s", id, err)
+	}
+	return nil
+}
+
+// ListPolicies returns the policies of the datacenter, without their rules.
+func (c *aclClient) ListPolicies() ([]*consulapi.ACLPolicyListEntry, error) {
+	log.Printf(
+		"[DEBUG] Listing ACL policies in %s",
+		c.qOpts.Datacenter,
+	)
+	policies, _, err := c.client.PolicyList(c.qOpts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list Consul ACL policies: %s", err)
+	}
+	return policies, nil
+}
+
+// ReadPolicyByName returns the policy with the given name, or nil if it
+// does not exist.
+func (c *aclClient) ReadPolicyByName(name string) (*consulapi.ACLPolicy, error) {
+	log.Printf(
+		"[DEBUG] Reading ACL policy named '%s' in %s",
+		name, c.qOpts.Datacenter,
+	)
+	policy, _, err := c.client.PolicyReadByName(name, c.qOpts)
+	if isACLNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read Consul ACL policy named '%s': %s", name, err)
+	}
+	return policy, nil
+}
+
+// ListTokens returns the tokens of the datacenter, without their secret IDs.
+func (c *aclClient) ListTokens() ([]*consulapi.ACLTokenListEntry, error) {
+	log.Printf(
+		"[DEBUG] Listing ACL tokens in %s",
+		c.qOpts.Datacenter,
+	)
+	tokens, _, err := c.client.TokenList(c.qOpts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list Consul ACL tokens: %s", err)
+	}
+	return tokens, nil
+}
+
+// ReadReplication returns the status of the replication of the ACLs from
+// the primary datacenter.
+func (c *aclClient) ReadReplication() (*consulapi.ACLReplicationStatus, error) {
+	log.Printf(
+		"[DEBUG] Reading ACL replication status in %s",
+		c.qOpts.Datacenter,
+	)
+	status, _, err := c.client.Replication(c.qOpts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read Consul ACL replication status: %s", err)
+	}
+	return status, nil
+}
@@ -0,0 +1,251 @@
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// ACL rules are parsed into a flat set of policies, keyed by the path of
+// each policy in the rules: the resource type, the name of the resource
+// for the types that have one, and the attribute, joined with
+// aclRulePathSeparator. Rules are equivalent when they have the same set,
+// whatever their syntax, HCL or JSON, and layout.
+const aclRulePathSeparator = "\x00"
+
+// aclRuleScalars are the resource types whose policy is given directly,
+// as in `operator = "read"`.
+var aclRuleScalars = map[string]bool{
+	"acl":      true,
+	"keyring":  true,
+	"mesh":     true,
+	"operator": true,
+	"peering":  true,
+}
+
+// aclRuleResources are the resource types whose policies are given by
+// name, as in `key_prefix "app/" { policy = "read" }`, with the attributes
+// their blocks accept.
+var aclRuleResources = map[string][]string{
+	"agent":            {"policy"},
+	"agent_prefix":     {"policy"},
+	"event":            {"policy"},
+	"event_prefix":     {"policy"},
+	"identity":         {"policy", "intentions"},
+	"identity_prefix":  {"policy", "intentions"},
+	"key":              {"policy"},
+	"key_prefix":       {"policy"},
+	"node":             {"policy"},
+	"node_prefix":      {"policy"},
+	"query":            {"policy"},
+	"query_prefix":     {"policy"},
+	"service":          {"policy", "intentions"},
+	"service_prefix":   {"policy", "intentions"},
+	"session":          {"policy"},
+	"session_prefix":   {"policy"},
+	"namespace":        {"policy"},
+	"namespace_prefix": {"policy"},
+	"partition":        {"policy"},
+	"partition_prefix": {"policy"},
+}
+
+// aclRuleNamespaces are the resource types whose blocks also hold the
+// rules applying within the namespaces or admin partitions they match.
+var aclRuleNamespaces = map[string]bool{
+	"namespace":        true,
+	"namespace_prefix": true,
+	"partition":        true,
+	"partition_prefix": true,
+}
+
+// parseACLRules parses the ACL rules in HCL or JSON, checking the resource
+// types, attributes and policies they use.
+func parseACLRules(rules string) (map[string]string, error) {
+	policies := make(map[string]string)
+	if strings.TrimSpace(rules) == "" {
+		return policies, nil
+	}
+
+	file, err := hcl.Parse(rules)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse ACL rules: %v", err)
+	}
+	list, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("Failed to parse ACL rules: expected an object")
+	}
+	if err := parseACLRuleList(policies, nil, list); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func parseACLRuleList(policies map[string]string, path []string, list *ast.ObjectList) error {
+	for _, item := range list.Items {
+		resourceType, err := aclRuleKey(item.Keys[0])
+		if err != nil {
+			return err
+		}
+		line := item.Keys[0].Pos().Line
+
+		if aclRuleScalars[resourceType] {
+			if len(item.Keys) != 1 {
+				return aclRuleError(line, "'%s' takes a policy, not a block", resourceType)
+			}
+			if err := parseACLRulePolicy(policies, append(path, resourceType), resourceType, item.Val, line); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, ok := aclRuleResources[resourceType]; !ok {
+			return aclRuleError(line, "unknown ACL resource type '%s'", resourceType)
+		}
+
+		switch len(item.Keys) {
+		case 2:
+			// key "name" { ... }
+			name, err := aclRuleKey(item.Keys[1])
+			if err != nil {
+				return err
+			}
+			if err := parseACLRuleBlock(policies, path, resourceType, name, item.Val, line); err != nil {
+				return err
+			}
+		case 1:
+			// key = { "name" = { ... } }, as written in JSON.
+			names, ok := item.Val.(*ast.ObjectType)
+			if !ok {
+				return aclRuleError(line, "'%s' rules must be blocks", resourceType)
+			}
+			for _, nameItem := range names.List.Items {
+				name, err := aclRuleKey(nameItem.Keys[0])
+				if err != nil {
+					return err
+				}
+				if len(nameItem.Keys) != 1 {
+					return aclRuleError(nameItem.Keys[1].Pos().Line, "unexpected key in '%s' rule '%s'", resourceType, name)
+				}
+				if err := parseACLRuleBlock(policies, path, resourceType, name, nameItem.Val, nameItem.Keys[0].Pos().Line); err != nil {
+					return err
+				}
+			}
+		default:
+			return aclRuleError(item.Keys[2].Pos().Line, "unexpected key in '%s' rule", resourceType)
+		}
+	}
+	return nil
+}
+
+func parseACLRuleBlock(policies map[string]string, path []string, resourceType, name string, val ast.Node, line int) error {
+	block, ok := val.(*ast.ObjectType)
+	if !ok {
+		return aclRuleError(line, "'%s' rule '%s' must be a block", resourceType, name)
+	}
+
+	path = append(append([]string{}, path...), resourceType, name)
+	var nested ast.ObjectList
+	for _, item := range block.List.Items {
+		attr, err := aclRuleKey(item.Keys[0])
+		if err != nil {
+			return err
+		}
+		attrLine := item.Keys[0].Pos().Line
+
+		if !containsString(aclRuleResources[resourceType], attr) {
+			if aclRuleNamespaces[resourceType] {
+				nested.Add(item)
+				continue
+			}
+			return aclRuleError(attrLine, "unknown attribute '%s' in '%s' rule '%s'", attr, resourceType, name)
+		}
+		if len(item.Keys) != 1 {
+			return aclRuleError(attrLine, "'%s' takes a policy, not a block", attr)
+		}
+		if err := parseACLRulePolicy(policies, append(path, attr), resourceType, item.Val, attrLine); err != nil {
+			return err
+		}
+	}
+
+	return parseACLRuleList(policies, path, &nested)
+}
+
+func parseACLRulePolicy(policies map[string]string, path []string, resourceType string, val ast.Node, line int) error {
+	lit, ok := val.(*ast.LiteralType)
+	if !ok {
+		return aclRuleError(line, "the policy of '%s' must be a string", resourceType)
+	}
+	policy, ok := lit.Token.Value().(string)
+	if !ok {
+		return aclRuleError(line, "the policy of '%s' must be a string", resourceType)
+	}
+
+	switch policy {
+	case "read", "write", "deny":
+	case "list":
+		if resourceType != "key" && resourceType != "key_prefix" {
+			return aclRuleError(line, "invalid policy 'list' for '%s': only key and key_prefix rules accept it", resourceType)
+		}
+	default:
+		return aclRuleError(line, "invalid policy '%s' for '%s': expected read, write or deny", policy, resourceType)
+	}
+
+	policies[strings.Join(path, aclRulePathSeparator)] = policy
+	return nil
+}
+
+// aclRuleKey returns the unquoted text of key.
+func aclRuleKey(key *ast.ObjectKey) (string, error) {
+	v, ok := key.Token.Value().(string)
+	if !ok {
+		return "", aclRuleError(key.Pos().Line, "unexpected key %s", key.Token.Text)
+	}
+	return v, nil
+}
+
+// aclRuleError returns an error located at line of the rules. The rules
+// parsed from JSON have no positions, so their errors only name the rule.
+func aclRuleError(line int, format string, a ...interface{}) error {
+	if line > 0 {
+		return fmt.Errorf("Line %d: "+format, append([]interface{}{line}, a...)...)
+	}
+	return fmt.Errorf(format, a...)
+}
+
+// containsString reports whether s is one of values.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// validateACLRules is a ValidateFunc reporting the errors of ACL rules at
+// plan time rather than when Consul rejects them.
+func validateACLRules(v interface{}, key string) (warnings []string, errors []error) {
+	if _, err := parseACLRules(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("Invalid %s: %v", key, err))
+	}
+	return warnings, errors
+}
+
+// suppressEquivalentACLRules suppresses the differences between ACL rules
+// granting the same policies, such as the same rules in HCL and in JSON,
+// or with a different layout or order.
+func suppressEquivalentACLRules(k, old, new string, d *schema.ResourceData) bool {
+	oldPolicies, err := parseACLRules(old)
+	if err != nil {
+		return false
+	}
+	newPolicies, err := parseACLRules(new)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(oldPolicies, newPolicies)
+}
@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseACLRules(t *testing.T) {
+	hclRules := `
+operator = "read"
+
+key_prefix "app/" {
+  policy = "write"
+}
+
+service "" {
+  policy     = "read"
+  intentions = "deny"
+}
+
+namespace "team" {
+  policy = "read"
+
+  key "config" {
+    policy = "list"
+  }
+}
+`
+	jsonRules := `{
+  "namespace": {"team": {"key": {"config": {"policy": "list"}}, "policy": "read"}},
+  "service": {"": {"intentions": "deny", "policy": "read"}},
+  "key_prefix": {"app/": {"policy": "write"}},
+  "operator": "read"
+}`
+
+	want := map[string]string{
+		"operator":                                     "read",
+		"key_prefix\x00app/\x00policy":                 "write",
+		"service\x00\x00policy":                        "read",
+		"service\x00\x00intentions":                    "deny",
+		"namespace\x00team\x00policy":                  "read",
+		"namespace\x00team\x00key\x00config\x00policy": "list",
+	}
+	for _, rules := range []string{hclRules, jsonRules} {
+		policies, err := parseACLRules(rules)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(policies, want) {
+			t.Fatalf("bad policies: %#v", policies)
+		}
+	}
+
+	if !suppressEquivalentACLRules("rules", hclRules, jsonRules, nil) {
+		t.Fatalf("expected the HCL and JSON rules to be equivalent")
+	}
+	if suppressEquivalentACLRules("rules", `key "a" { policy = "read" }`, `key "a" { policy = "write" }`, nil) {
+		t.Fatalf("expected different policies not to be equivalent")
+	}
+
+	// The resource types added by newer Consul versions are accepted.
+	newer := `
+mesh    = "write"
+peering = "read"
+
+identity_prefix "web" {
+  policy     = "write"
+  intentions = "read"
+}
+
+partition "ops" {
+  mesh = "read"
+
+  namespace_prefix "" {
+    identity "db" {
+      policy = "read"
+    }
+  }
+}
+`
+	policies, err := parseACLRules(newer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = map[string]string{
+		"mesh":                                 "write",
+		"peering":                              "read",
+		"identity_prefix\x00web\x00policy":     "write",
+		"identity_prefix\x00web\x00intentions": "read",
+		"partition\x00ops\x00mesh":             "read",
+		"partition\x00ops\x00namespace_prefix\x00\x00identity\x00db\x00policy": "read",
+	}
+	if !reflect.DeepEqual(policies, want) {
+		t.Fatalf("bad policies: %#v", policies)
+	}
+
+	errors := map[string]string{
+		"key \"a\" {\n  policy = \"read\"\n":                         "Failed to parse ACL rules: ",
+		"\n\nkeys \"a\" { policy = \"read\" }":                       "Line 3: unknown ACL resource type 'keys'",
+		"key \"a\" {\n  policy = \"reed\"\n}":                        "Line 2: invalid policy 'reed' for 'key': expected read, write or deny",
+		"service \"a\" {\n  policy = \"list\"\n}":                    "Line 2: invalid policy 'list' for 'service': only key and key_prefix rules accept it",
+		"node \"a\" {\n  intentions = \"read\"\n}":                   "Line 2: unknown attribute 'intentions' in 'node' rule 'a'",
+		"operator \"a\" { policy = \"read\" }":                       "Line 1: 'operator' takes a policy, not a block",
+		"{\n  \"key\": {\n    \"a\": {\"policy\": \"none\"}\n  }\n}": "invalid policy 'none' for 'key': expected read, write or deny",
+	}
+	for rules, prefix := range errors {
+		_, err := parseACLRules(rules)
+		if err == nil || len(err.Error()) < len(prefix) || err.Error()[:len(prefix)] != prefix {
+			t.Fatalf("rules %q: expected an error starting with %q, got %v", rules, prefix, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"net/http"
+	"sync"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// clientPool is a concurrency-safe cache of Consul clients shared by every
+// resource and data source of a provider instance.
+//
+// Clients are keyed by the fully resolved connection settings, so resources
+// pointing at the same endpoint with the same credentials share a single
+// client. Clients that only differ by token, datacenter or HTTP auth share the
+// same HTTP transport, so that connections are reused across all of them.
+// The datacenter of the agent behind each endpoint is looked up once and
+// memoized.
+type clientPool struct {
+	lock        sync.Mutex
+	clients     map[ProviderConfig]*consulapi.Client
+	transports  map[ProviderConfig]*http.Transport
+	datacenters map[ProviderConfig]string
+}
+
+func newClientPool() *clientPool {
+	return &clientPool{
+		clients:     make(map[ProviderConfig]*consulapi.Client),
+		transports:  make(map[ProviderConfig]*http.Transport),
+		datacenters: make(map[ProviderConfig]string),
+	}
+}
+
+// clientKey returns the key identifying a client configured with c.
+func clientKey(c *ProviderConfig) ProviderConfig {
+	return ProviderConfig{
+		Datacenter: c.Datacenter,
+		Host:       c.Host,
+		Scheme:     c.Scheme,
+		HttpAuth:   c.HttpAuth,
+		Token:      c.Token,
+		CAFile:     c.CAFile,
+		CertFile:   c.CertFile,
+		KeyFile:    c.KeyFile,
+	}
+}
+
+// transportKey returns the key identifying the HTTP transport used to reach
+// the endpoint described by c.
+func transportKey(c *ProviderConfig) ProviderConfig {
+	return ProviderConfig{
+		Host:     c.Host,
+		Scheme:   c.Scheme,
+		CAFile:   c.CAFile,
+		CertFile: c.CertFile,
+		KeyFile:  c.KeyFile,
+	}
+}
+
+// endpointKey returns the key identifying the agent reached through c,
+// regardless of the token or datacenter the requests are issued for.
+func endpointKey(c *ProviderConfig) ProviderConfig {
+	return ProviderConfig{
+		Host:     c.Host,
+		Scheme:   c.Scheme,
+		HttpAuth: c.HttpAuth,
+		CAFile:   c.CAFile,
+		CertFile: c.CertFile,
+		KeyFile:  c.KeyFile,
+	}
+}
+
+// Client returns the client configured with c, creating it if needed.
+func (p *clientPool) Client(c *ProviderConfig) (*consulapi.Client, error) {
+	key := clientKey(c)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if client, ok := p.clients[key]; ok {
+		return client, nil
+	}
+
+	tKey := transportKey(c)
+	transport, ok := p.transports[tKey]
+	if !ok {
+		var err error
+		if transport, err = newTransport(c); err != nil {
+			return nil, err
+		}
+		p.transports[tKey] = transport
+	}
+
+	client, err := newClient(c, transport)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[key] = client
+	return client, nil
+}
+
+// Datacenter returns the datacenter of the agent reached through client,
+// which must have been configured with c. The lookup is only performed once
+// per endpoint.
+func (p *clientPool) Datacenter(c *ProviderConfig, client *consulapi.Client) (string, error) {
+	key := endpointKey(c)
+
+	p.lock.Lock()
+	dc, ok := p.datacenters[key]
+	p.lock.Unlock()
+	if ok {
+		return dc, nil
+	}
+
+	// The lock is not held while querying the agent: concurrent lookups
+	// against the same endpoint are harmless and all yield the same value.
+	dc, err := agentDatacenter(client)
+	if err != nil {
+		return "", err
+	}
+
+	p.lock.Lock()
+	p.datacenters[key] = dc
+	p.lock.Unlock()
+	return dc, nil
+}
@@ -4,11 +4,13 @@
 package provider
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
 
 	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/mitchellh/mapstructure"
 )
@@ -22,11 +24,45 @@ type ProviderConfig struct {
 	CAFile     string `mapstructure:"ca_file"`
 	CertFile   string `mapstructure:"cert_file"`
 	KeyFile    string `mapstructure:"key_file"`
+
+	// TokenFile and TokenCommand are the sources of the token, when it is
+	// not given directly. GetResolvedConfig reads the token from them, so
+	// only their paths and commands are ever stored in the state.
+	TokenFile    string `mapstructure:"token_file"`
+	TokenCommand string `mapstructure:"token_command"`
+
+	// pool is shared by the provider configuration and every configuration
+	// resolved from it.
+	pool *clientPool
+
+	// sessions keeps alive the sessions renewed by this provider instance.
+	sessions *sessionRenewer
+
+	// tokens caches the tokens printed by token commands.
+	tokens *tokenCache
+}
+
+// resourceConfigKeys are the attributes through which resources override the
+// connection settings of the provider.
+var resourceConfigKeys = []string{
+	"datacenter", "host", "scheme", "http_auth", "token", "token_file", "token_command",
+	"ca_file", "cert_file", "key_file",
 }
 
 func (c *ProviderConfig) GetResolvedConfig(d *schema.ResourceData) (*ProviderConfig, bool, error) {
 	var r, n ProviderConfig
-	configRaw := d.Get("").(map[string]interface{})
+	r.pool = c.pool
+	r.sessions = c.sessions
+	r.tokens = c.tokens
+	// The connection settings are read one by one, as reading the whole
+	// resource with d.Get("") only returns the attributes set with d.Set
+	// once any has been set during an apply.
+	configRaw := make(map[string]interface{})
+	for _, k := range resourceConfigKeys {
+		if v, ok := d.GetOk(k); ok {
+			configRaw[k] = v
+		}
+	}
 	if err := mapstructure.Decode(configRaw, &n); err != nil {
 		return nil, false, err
 	}
@@ -54,11 +90,13 @@ func (c *ProviderConfig) GetResolvedConfig(d *schema.ResourceData) (*ProviderCon
 	case c.HttpAuth != "":
 		r.HttpAuth = c.HttpAuth
 	}
+	// The token of the resource, whatever its source, takes precedence
+	// over the one of the provider.
 	switch {
-	case n.Token != "":
-		r.Token = n.Token
-	case c.Token != "":
-		r.Token = c.Token
+	case n.Token != "" || n.TokenFile != "" || n.TokenCommand != "":
+		r.Token, r.TokenFile, r.TokenCommand = n.Token, n.TokenFile, n.TokenCommand
+	default:
+		r.Token, r.TokenFile, r.TokenCommand = c.Token, c.TokenFile, c.TokenCommand
 	}
 	switch {
 	case n.CAFile != "":
@@ -78,22 +116,60 @@ func (c *ProviderConfig) GetResolvedConfig(d *schema.ResourceData) (*ProviderCon
 	case c.KeyFile != "":
 		r.KeyFile = c.KeyFile
 	}
+	if err := r.resolveToken(); err != nil {
+		return nil, false, err
+	}
 	return &r, false, nil
 }
 
-// NewClient() returns a new client for accessing consul.
+// resolveToken sets the token of c from its token file or token command,
+// if any. The token file is read again each time, to pick up the tokens
+// rotated since, while the output of the token command is cached.
+func (c *ProviderConfig) resolveToken() error {
+	switch {
+	case c.TokenFile != "":
+		token, err := readTokenFile(c.TokenFile)
+		if err != nil {
+			return err
+		}
+		c.Token = token
+	case c.TokenCommand != "":
+		token, err := c.tokens.Command(c.TokenCommand)
+		if err != nil {
+			return err
+		}
+		c.Token = token
+	}
+	return nil
+}
+
+// NewClient() returns a client for accessing consul. Clients are shared
+// through the provider's client pool when one is available.
 func (c *ProviderConfig) NewClient() (*consulapi.Client, error) {
-	config := consulapi.DefaultConfig()
-	if c.Datacenter != "" {
-		config.Datacenter = c.Datacenter
+	if c.pool != nil {
+		return c.pool.Client(c)
 	}
-	if c.Host != "" {
-		config.Address = c.Host
+
+	transport, err := newTransport(c)
+	if err != nil {
+		return nil, err
 	}
-	if c.Scheme != "" {
-		config.Scheme = c.Scheme
+	return newClient(c, transport)
+}
+
+// getDC is used to get the datacenter of the local agent
+func (c *ProviderConfig) getDC(d *schema.ResourceData, client *consulapi.Client) (string, error) {
+	if v, ok := d.GetOk("datacenter"); ok {
+		return v.(string), nil
+	}
+	if c.pool != nil {
+		return c.pool.Datacenter(c, client)
 	}
+	return agentDatacenter(client)
+}
 
+// newTransport returns a pooled HTTP transport with the TLS settings of c.
+func newTransport(c *ProviderConfig) (*http.Transport, error) {
 	tlsConfig := &consulapi.TLSConfig{}
 	tlsConfig.CAFile = c.CAFile
 	tlsConfig.CertFile = c.CertFile
@@ -102,7 +178,27 @@ func (c *ProviderConfig) NewClient() (*consulapi.Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	config.HttpClient.Transport.(*http.Transport).TLSClientConfig = cc
+
+	transport := cleanhttp.DefaultPooledTransport()
+	transport.TLSClientConfig = cc
+	return transport, nil
+}
+
+// newClient returns a new client configured with c which sends its requests
+// through transport.
+func newClient(c *ProviderConfig, transport *http.Transport) (*consulapi.Client, error) {
+	config := consulapi.DefaultConfig()
+	if c.Datacenter != "" {
+		config.Datacenter = c.Datacenter
+	}
+	if c.Host != "" {
+		config.Address = c.Host
+	}
+	if c.Scheme != "" {
+		config.Scheme = c.Scheme
+	}
+
+	config.HttpClient = &http.Client{Transport: transport}
 
 	if c.HttpAuth != "" {
 		var username, password string
@@ -129,3 +225,13 @@ func (c *ProviderConfig) NewClient() (*consulapi.Client, error) {
 	}
 	return client, nil
 }
+
+// agentDatacenter queries the agent reached through client for its
+// datacenter.
+func agentDatacenter(client *consulapi.Client) (string, error) {
+	info, err := client.Agent().Self()
+	if err != nil {
+		return "", fmt.Errorf("Failed to get datacenter from Consul agent: %v", err)
+	}
+	return info["Config"]["Datacenter"].(string), nil
+}
@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceConsulACLPolicies() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConsulACLPoliciesRead,
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"policies": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"datacenters": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceConsulACLPoliciesRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	policies, err := aclClient.ListPolicies()
+	if err != nil {
+		return err
+	}
+
+	names := make([]interface{}, 0, len(policies))
+	list := make([]interface{}, 0, len(policies))
+	for _, policy := range policies {
+		names = append(names, policy.Name)
+		list = append(list, map[string]interface{}{
+			"id":          policy.ID,
+			"name":        policy.Name,
+			"description": policy.Description,
+			"datacenters": policy.Datacenters,
+		})
+	}
+	if err := d.Set("names", names); err != nil {
+		return fmt.Errorf("Unable to store ACL policy names: %v", err)
+	}
+	if err := d.Set("policies", list); err != nil {
+		return fmt.Errorf("Unable to store ACL policies: %v", err)
+	}
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	d.SetId(fmt.Sprintf("acl-policies-%s", dc))
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataConsulACLPolicies_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulACLPoliciesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_acl_policies.read", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_policies.read", "names.#", "2"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_policies.read", "names.0", "app"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_policies.read", "policies.#", "2"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_policies.read", "policies.0.description", "Application policy"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_policies.read", "policies.0.datacenters.0", "dc1"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataConsulACLPoliciesConfig = `
+resource "consulclient_acl_policy" "app" {
+	name        = "app"
+	description = "Application policy"
+	rules       = "key_prefix \"app/\" { policy = \"read\" }"
+	datacenters = ["dc1"]
+}
+
+resource "consulclient_acl_policy" "admin" {
+	name        = "admin"
+	description = "Administration policy for ${consulclient_acl_policy.app.id}"
+	rules       = "operator = \"write\""
+}
+
+data "consulclient_acl_policies" "read" {
+	datacenter = "${consulclient_acl_policy.admin.datacenter}"
+}
+`
@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceConsulACLPolicy() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConsulACLPolicyRead,
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"rules": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"datacenters": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceConsulACLPolicyRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	name := d.Get("name").(string)
+	policy, err := aclClient.ReadPolicyByName(name)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return fmt.Errorf("ACL policy '%s' not found", name)
+	}
+
+	d.Set("description", policy.Description)
+	d.Set("rules", policy.Rules)
+	if err := d.Set("datacenters", policy.Datacenters); err != nil {
+		return fmt.Errorf("Unable to store ACL policy datacenters: %v", err)
+	}
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	d.SetId(policy.ID)
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataConsulACLPolicy_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulACLPolicyConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_acl_policy.read", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_policy.read", "description", "Application policy"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_policy.read", "rules", `key_prefix "app/" { policy = "read" }`),
+					resource.TestCheckResourceAttr("data.consulclient_acl_policy.read", "datacenters.#", "1"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_policy.read", "datacenters.0", "dc1"),
+					resource.TestCheckResourceAttrPair("data.consulclient_acl_policy.read", "id", "consulclient_acl_policy.app", "id"),
+				),
+			},
+			{
+				Config:      testAccProviderConfig(consul) + testAccDataConsulACLPolicyConfig_missing,
+				ExpectError: regexp.MustCompile("ACL policy 'missing' not found"),
+			},
+		},
+	})
+}
+
+const testAccDataConsulACLPolicyConfig = `
+resource "consulclient_acl_policy" "app" {
+	name        = "app"
+	description = "Application policy"
+	rules       = "key_prefix \"app/\" { policy = \"read\" }"
+	datacenters = ["dc1"]
+}
+
+data "consulclient_acl_policy" "read" {
+	datacenter = "${consulclient_acl_policy.app.datacenter}"
+	name       = "app"
+}
+`
+
+const testAccDataConsulACLPolicyConfig_missing = `
+data "consulclient_acl_policy" "read" {
+	name = "missing"
+}
+`
@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"fmt"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceConsulACLReplication() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConsulACLReplicationRead,
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			// require_healthy fails the read, and so the plan, while the
+			// replication is not healthy, to gate the changes made in a
+			// secondary datacenter on it.
+			"require_healthy": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"enabled": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"running": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"healthy": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"source_datacenter": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"replication_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"replicated_index": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"replicated_role_index": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"replicated_token_index": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"last_success": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"last_error": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceConsulACLReplicationRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	status, err := aclClient.ReadReplication()
+	if err != nil {
+		return err
+	}
+
+	healthy := isACLReplicationHealthy(status)
+	if d.Get("require_healthy").(bool) && !healthy {
+		return fmt.Errorf("ACL replication in %s is not healthy: enabled: %t, running: %t, last success: %s, last error: %s",
+			dc, status.Enabled, status.Running, formatACLReplicationTime(status.LastSuccess), formatACLReplicationTime(status.LastError))
+	}
+
+	d.Set("enabled", status.Enabled)
+	d.Set("running", status.Running)
+	d.Set("healthy", healthy)
+	d.Set("source_datacenter", status.SourceDatacenter)
+	d.Set("replication_type", status.ReplicationType)
+	d.Set("replicated_index", int(status.ReplicatedIndex))
+	d.Set("replicated_role_index", int(status.ReplicatedRoleIndex))
+	d.Set("replicated_token_index", int(status.ReplicatedTokenIndex))
+	d.Set("last_success", formatACLReplicationTime(status.LastSuccess))
+	d.Set("last_error", formatACLReplicationTime(status.LastError))
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	d.SetId(fmt.Sprintf("acl-replication-%s", dc))
+
+	return nil
+}
+
+// isACLReplicationHealthy returns whether the replication is running and
+// its last run succeeded.
+func isACLReplicationHealthy(status *consulapi.ACLReplicationStatus) bool {
+	return status.Enabled && status.Running && !status.LastSuccess.IsZero() &&
+		!status.LastError.After(status.LastSuccess)
+}
+
+// formatACLReplicationTime returns t in RFC 3339 format, or "" if the
+// replication never reached that point.
+func formatACLReplicationTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataConsulACLReplication_basic(t *testing.T) {
+	consul := newFakeConsul("dc2")
+	defer consul.Close()
+
+	lastSuccess := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	setReplication := func(status *consulapi.ACLReplicationStatus) func() {
+		return func() {
+			consul.lock.Lock()
+			defer consul.lock.Unlock()
+			consul.aclReplication = status
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulACLReplicationConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "datacenter", "dc2"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "enabled", "false"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "healthy", "false"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "last_success", ""),
+				),
+			},
+			{
+				PreConfig: setReplication(&consulapi.ACLReplicationStatus{
+					Enabled:              true,
+					Running:              true,
+					SourceDatacenter:     "dc1",
+					ReplicationType:      "tokens",
+					ReplicatedIndex:      42,
+					ReplicatedRoleIndex:  43,
+					ReplicatedTokenIndex: 44,
+					LastSuccess:          lastSuccess,
+				}),
+				Config: testAccProviderConfig(consul) + testAccDataConsulACLReplicationConfig_requireHealthy,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "enabled", "true"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "running", "true"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "healthy", "true"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "source_datacenter", "dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "replication_type", "tokens"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "replicated_index", "42"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "replicated_role_index", "43"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "replicated_token_index", "44"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "last_success", "2020-05-01T12:00:00Z"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_replication.read", "last_error", ""),
+				),
+			},
+			{
+				PreConfig: setReplication(&consulapi.ACLReplicationStatus{
+					Enabled:          true,
+					Running:          true,
+					SourceDatacenter: "dc1",
+					ReplicationType:  "tokens",
+					LastSuccess:      lastSuccess,
+					LastError:        lastSuccess.Add(time.Minute),
+				}),
+				Config:      testAccProviderConfig(consul) + testAccDataConsulACLReplicationConfig_requireHealthy,
+				ExpectError: regexp.MustCompile("ACL replication in dc2 is not healthy.*last error: 2020-05-01T12:01:00Z"),
+			},
+		},
+	})
+}
+
+const testAccDataConsulACLReplicationConfig = `
+data "consulclient_acl_replication" "read" {}
+`
+
+const testAccDataConsulACLReplicationConfig_requireHealthy = `
+data "consulclient_acl_replication" "read" {
+	require_healthy = true
+}
+`
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceConsulACLTokenSecretID() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConsulACLTokenSecretIDRead,
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			"accessor_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"secret_id": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func dataSourceConsulACLTokenSecretIDRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	accessorID := d.Get("accessor_id").(string)
+	aclToken, err := aclClient.ReadToken(accessorID)
+	if err != nil {
+		return err
+	}
+	if aclToken == nil {
+		return fmt.Errorf("ACL token '%s' not found", accessorID)
+	}
+
+	d.Set("secret_id", aclToken.SecretID)
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	d.SetId(accessorID)
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataConsulACLTokenSecretID_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	consul.aclTokens["00000000-0000-0000-0000-00000000000a"] = &consulapi.ACLToken{
+		AccessorID:  "00000000-0000-0000-0000-00000000000a",
+		SecretID:    "00000000-0000-0000-0000-0000000000aa",
+		Description: "Application token",
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulACLTokenSecretIDConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_acl_token_secret_id.app", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_token_secret_id.app", "id", "00000000-0000-0000-0000-00000000000a"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_token_secret_id.app", "secret_id", "00000000-0000-0000-0000-0000000000aa"),
+					testAccCheckConsulACLTokenSecretID(consul, "data.consulclient_acl_token_secret_id.app"),
+				),
+			},
+			{
+				Config:      testAccProviderConfig(consul) + testAccDataConsulACLTokenSecretIDConfig_missing,
+				ExpectError: regexp.MustCompile("ACL token '00000000-0000-0000-0000-00000000000f' not found"),
+			},
+		},
+	})
+}
+
+const testAccDataConsulACLTokenSecretIDConfig = `
+data "consulclient_acl_token_secret_id" "app" {
+	accessor_id = "00000000-0000-0000-0000-00000000000a"
+}
+`
+
+const testAccDataConsulACLTokenSecretIDConfig_missing = `
+data "consulclient_acl_token_secret_id" "app" {
+	accessor_id = "00000000-0000-0000-0000-00000000000f"
+}
+`
@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceConsulACLTokens() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConsulACLTokensRead,
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			// Filters, by name. Only the tokens matching all the filters
+			// set are listed.
+			"policy": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"role": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"auth_method": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"accessor_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"tokens": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"accessor_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"policies": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"roles": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"local": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"auth_method": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"expiration_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceConsulACLTokensRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	tokens, err := aclClient.ListTokens()
+	if err != nil {
+		return err
+	}
+
+	policy := d.Get("policy").(string)
+	role := d.Get("role").(string)
+	authMethod := d.Get("auth_method").(string)
+
+	accessorIDs := make([]interface{}, 0, len(tokens))
+	list := make([]interface{}, 0, len(tokens))
+	for _, t := range tokens {
+		policies := flattenACLLinks(t.Policies)
+		roles := flattenACLLinks(t.Roles)
+		if policy != "" && !containsString(policies, policy) {
+			continue
+		}
+		if role != "" && !containsString(roles, role) {
+			continue
+		}
+		if authMethod != "" && t.AuthMethod != authMethod {
+			continue
+		}
+
+		accessorIDs = append(accessorIDs, t.AccessorID)
+		list = append(list, map[string]interface{}{
+			"accessor_id":     t.AccessorID,
+			"description":     t.Description,
+			"policies":        policies,
+			"roles":           roles,
+			"local":           t.Local,
+			"auth_method":     t.AuthMethod,
+			"expiration_time": formatACLExpirationTime(t.ExpirationTime),
+		})
+	}
+	if err := d.Set("accessor_ids", accessorIDs); err != nil {
+		return fmt.Errorf("Unable to store ACL token accessor IDs: %v", err)
+	}
+	if err := d.Set("tokens", list); err != nil {
+		return fmt.Errorf("Unable to store ACL tokens: %v", err)
+	}
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	d.SetId(fmt.Sprintf("acl-tokens-%s-%s-%s-%s", dc, policy, role, authMethod))
+
+	return nil
+}
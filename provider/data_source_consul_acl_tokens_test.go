@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"testing"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataConsulACLTokens_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	// A token created by logging in with an auth method.
+	consul.aclTokens["00000000-0000-0000-0000-00000000000c"] = &consulapi.ACLToken{
+		AccessorID:  "00000000-0000-0000-0000-00000000000c",
+		SecretID:    "00000000-0000-0000-0000-0000000000cc",
+		Description: "token created via login",
+		Local:       true,
+		AuthMethod:  "minikube",
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulACLTokensConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.all", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.all", "accessor_ids.#", "3"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.all", "tokens.#", "3"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.all", "tokens.0.description", "Application token"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.all", "tokens.0.policies.0", "app"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.all", "tokens.0.local", "false"),
+					resource.TestCheckNoResourceAttr("data.consulclient_acl_tokens.all", "tokens.0.secret_id"),
+
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.app", "accessor_ids.#", "1"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.app", "accessor_ids.0", "00000000-0000-0000-0000-00000000000a"),
+
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.ops", "accessor_ids.#", "1"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.ops", "accessor_ids.0", "00000000-0000-0000-0000-00000000000b"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.ops", "tokens.0.roles.0", "ops"),
+
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.login", "accessor_ids.#", "1"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.login", "accessor_ids.0", "00000000-0000-0000-0000-00000000000c"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.login", "tokens.0.auth_method", "minikube"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.login", "tokens.0.local", "true"),
+
+					resource.TestCheckResourceAttr("data.consulclient_acl_tokens.none", "accessor_ids.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataConsulACLTokensConfig = `
+resource "consulclient_acl_policy" "app" {
+	name  = "app"
+	rules = "key_prefix \"app/\" { policy = \"read\" }"
+}
+
+resource "consulclient_acl_role" "ops" {
+	name = "ops"
+}
+
+resource "consulclient_acl_token" "app" {
+	accessor_id = "00000000-0000-0000-0000-00000000000a"
+	description = "Application token"
+	policies    = ["${consulclient_acl_policy.app.name}"]
+}
+
+resource "consulclient_acl_token" "ops" {
+	accessor_id = "00000000-0000-0000-0000-00000000000b"
+	description = "Operations token"
+	roles       = ["${consulclient_acl_role.ops.name}"]
+	depends_on  = ["consulclient_acl_token.app"]
+}
+
+data "consulclient_acl_tokens" "all" {
+	datacenter = "${consulclient_acl_token.ops.datacenter}"
+}
+
+data "consulclient_acl_tokens" "app" {
+	datacenter = "${consulclient_acl_token.app.datacenter}"
+	policy     = "app"
+}
+
+data "consulclient_acl_tokens" "ops" {
+	datacenter = "${consulclient_acl_token.ops.datacenter}"
+	role       = "ops"
+}
+
+data "consulclient_acl_tokens" "login" {
+	datacenter  = "${consulclient_acl_token.app.datacenter}"
+	auth_method = "minikube"
+}
+
+data "consulclient_acl_tokens" "none" {
+	datacenter  = "${consulclient_acl_token.ops.datacenter}"
+	policy      = "app"
+	auth_method = "minikube"
+}
+`
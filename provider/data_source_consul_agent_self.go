@@ -202,10 +202,9 @@ func dataSourceConsulAgentSelf() *schema.Resource {
 				Optional: true,
 			},
 
-			"token": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
 			agentSelfACLDatacenter: {
 				Computed: true,
 				Type:     schema.TypeString,
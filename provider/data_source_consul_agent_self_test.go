@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataConsulAgentSelf_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulAgentSelfConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_agent_self.read", "id", "agent-self-dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_agent_self.read", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_agent_self.read", "name", "agent-dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_agent_self.read", "server_mode", "true"),
+					resource.TestCheckResourceAttr("data.consulclient_agent_self.read", "version", "1.8.0"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataConsulAgentSelfConfig = `
+data "consulclient_agent_self" "read" {
+}
+`
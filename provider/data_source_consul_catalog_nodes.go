@@ -64,10 +64,9 @@ func dataSourceConsulCatalogNodes() *schema.Resource {
 				Optional: true,
 			},
 
-			"token": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
 
 			// Filters
 			catalogNodesQueryOpts: schemaQueryOpts,
@@ -143,7 +142,7 @@ func dataSourceConsulCatalogNodesRead(d *schema.ResourceData, meta interface{})
 	}
 
 	// Parse out data source filters to populate Consul's query options
-	queryOpts, err := getQueryOpts(d, client)
+	queryOpts, err := getQueryOpts(d, resolvedConfig, client)
 	if err != nil {
 		return errwrap.Wrapf("unable to get query options for fetching catalog nodes: {{err}}", err)
 	}
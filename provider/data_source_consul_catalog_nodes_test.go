@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataConsulCatalogNodes_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulCatalogNodesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_catalog_nodes.read", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_nodes.read", "nodes.#", "2"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_nodes.read", "nodes.0.name", "agent-dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_nodes.read", "nodes.1.name", "foo"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_nodes.read", "nodes.1.address", "127.0.0.10"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_nodes.read", "node_names.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataConsulCatalogNodesConfig = `
+resource "consulclient_node" "foo" {
+	name    = "foo"
+	address = "127.0.0.10"
+}
+
+data "consulclient_catalog_nodes" "read" {
+	query_options {
+		require_consistent = true
+		datacenter         = "${consulclient_node.foo.datacenter}"
+	}
+}
+`
@@ -70,10 +70,9 @@ func dataSourceConsulCatalogService() *schema.Resource {
 				Optional: true,
 			},
 
-			"token": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
 
 			// Data Source Predicate(s)
 			catalogServiceDatacenter: {
@@ -186,7 +185,7 @@ func dataSourceConsulCatalogServiceRead(d *schema.ResourceData, meta interface{}
 	}
 
 	// Parse out data source filters to populate Consul's query options
-	queryOpts, err := getQueryOpts(d, client)
+	queryOpts, err := getQueryOpts(d, resolvedConfig, client)
 	if err != nil {
 		return errwrap.Wrapf("unable to get query options for fetching catalog services: {{err}}", err)
 	}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataConsulCatalogService_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulCatalogServiceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_catalog_service.read", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_service.read", "name", "redis"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_service.read", "service.#", "1"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_service.read", "service.0.id", "redis1"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_service.read", "service.0.node_name", "foobar"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_service.read", "service.0.port", "8000"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_service.read", "service.0.tags.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataConsulCatalogServiceConfig = `
+resource "consulclient_catalog_entry" "app" {
+	address = "192.168.10.10"
+	node    = "foobar"
+
+	service {
+		id   = "redis1"
+		name = "redis"
+		port = 8000
+		tags = ["master", "v1"]
+	}
+}
+
+data "consulclient_catalog_service" "read" {
+	name = "redis"
+
+	query_options {
+		datacenter = "${consulclient_catalog_entry.app.datacenter}"
+	}
+}
+`
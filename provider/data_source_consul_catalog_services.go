@@ -72,7 +72,7 @@ func dataSourceConsulCatalogServicesRead(d *schema.ResourceData, meta interface{
 	}
 
 	// Parse out data source filters to populate Consul's query options
-	queryOpts, err := getQueryOpts(d, client)
+	queryOpts, err := getQueryOpts(d, resolvedConfig, client)
 	if err != nil {
 		return errwrap.Wrapf("unable to get query options for fetching catalog services: {{err}}", err)
 	}
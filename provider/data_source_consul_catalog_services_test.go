@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataConsulCatalogServices_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulCatalogServicesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_catalog_services.read", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_services.read", "names.#", "3"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_services.read", "services.%", "3"),
+					resource.TestCheckResourceAttr("data.consulclient_catalog_services.read", "services.redis", "master v1"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataConsulCatalogServicesConfig = `
+resource "consulclient_catalog_entry" "app" {
+	address = "192.168.10.10"
+	node    = "foobar"
+
+	service {
+		id   = "redis1"
+		name = "redis"
+		port = 8000
+		tags = ["v1", "master"]
+	}
+
+	service {
+		id   = "web1"
+		name = "web"
+		port = 80
+	}
+}
+
+data "consulclient_catalog_services" "read" {
+	query_options {
+		datacenter = "${consulclient_catalog_entry.app.datacenter}"
+	}
+}
+`
@@ -0,0 +1,288 @@
+package provider
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceConsulKeyPrefix() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConsulKeyPrefixRead,
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			"path_prefix": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"separator": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "/",
+			},
+
+			// depth limits the listing to the subkeys with at most depth
+			// components, 0 meaning no limit. The subtrees below it are
+			// listed in keys as a single subkey ending with the separator.
+			"depth": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+				ValidateFunc: makeValidationFunc("depth", []interface{}{
+					validateIntMin(0),
+				}),
+			},
+
+			// keys_only lists the subkeys without reading their values.
+			"keys_only": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			// include and exclude are glob patterns, as understood by
+			// path.Match, that the subkeys must respectively match and not
+			// match to be listed.
+			"include": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"exclude": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"keys": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"subkeys": {
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
+
+			// subkeys_base64 holds the values of all the subkeys
+			// base64-encoded, text or not, so that the values that are not
+			// UTF-8 text can be read as they are.
+			"subkeys_base64": {
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceConsulKeyPrefixRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	kv := client.KV()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	keyClient := newKeyClient(kv, dc, token)
+
+	pathPrefix := d.Get("path_prefix").(string)
+	separator := d.Get("separator").(string)
+	depth := d.Get("depth").(int)
+	if depth > 0 && separator == "" {
+		return fmt.Errorf("A separator is required to limit the depth of the listing")
+	}
+
+	include, err := expandKeyPrefixPatterns(d.Get("include").([]interface{}))
+	if err != nil {
+		return err
+	}
+	exclude, err := expandKeyPrefixPatterns(d.Get("exclude").([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	// Without a depth limit, the keys read with their values need not be
+	// listed separately.
+	keysOnly := d.Get("keys_only").(bool)
+	var paths []string
+	var values map[string]string
+	if keysOnly || depth > 0 {
+		paths, err = listKeysUnderPrefix(keyClient, pathPrefix, separator, depth)
+		if err != nil {
+			return err
+		}
+	}
+	if !keysOnly {
+		values, err = keyClient.GetUnderPrefix(pathPrefix)
+		if err != nil {
+			return err
+		}
+		if depth == 0 {
+			for subKey := range values {
+				paths = append(paths, pathPrefix+subKey)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(paths))
+	for _, p := range paths {
+		subKey := p[len(pathPrefix):]
+		if subKey == "" || !matchKeyPrefixPatterns(subKey, include, exclude) {
+			continue
+		}
+		keys = append(keys, subKey)
+	}
+	sort.Strings(keys)
+
+	subKeys := make(map[string]string)
+	subKeysBase64 := make(map[string]string)
+	for _, k := range keys {
+		value, ok := values[k]
+		if !ok {
+			continue
+		}
+		subKeys[k] = value
+		subKeysBase64[k] = encodeBase64Value(value)
+	}
+
+	if err := d.Set("keys", keys); err != nil {
+		return err
+	}
+	if err := d.Set("subkeys", subKeys); err != nil {
+		return err
+	}
+	if err := d.Set("subkeys_base64", subKeysBase64); err != nil {
+		return err
+	}
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	d.SetId(pathPrefix)
+
+	return nil
+}
+
+// listKeysUnderPrefix lists the keys under pathPrefix down to the given
+// depth, listing one level of keys at a time with the separator.
+func listKeysUnderPrefix(keyClient *keyClient, pathPrefix, separator string, depth int) ([]string, error) {
+	if depth == 0 {
+		return keyClient.GetKeysUnderPrefix(pathPrefix, "")
+	}
+
+	var paths []string
+	prefixes := []string{pathPrefix}
+	for level := 1; level <= depth && len(prefixes) > 0; level++ {
+		var next []string
+		for _, prefix := range prefixes {
+			keys, err := keyClient.GetKeysUnderPrefix(prefix, separator)
+			if err != nil {
+				return nil, err
+			}
+			for _, k := range keys {
+				// A subtree is listed with its subkeys at the next level,
+				// unless this is the last one. A key equal to the prefix
+				// is the placeholder of the subtree itself.
+				if k != prefix && strings.HasSuffix(k, separator) && level < depth {
+					next = append(next, k)
+					continue
+				}
+				paths = append(paths, k)
+			}
+		}
+		prefixes = next
+	}
+	return paths, nil
+}
+
+// expandKeyPrefixPatterns returns the glob patterns in l, checking that they
+// are valid.
+func expandKeyPrefixPatterns(l []interface{}) ([]string, error) {
+	patterns := make([]string, 0, len(l))
+	for _, raw := range l {
+		pattern := raw.(string)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("Invalid pattern '%s': %v", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// matchKeyPrefixPatterns returns whether subKey matches one of the include
+// patterns, if any, and none of the exclude patterns.
+func matchKeyPrefixPatterns(subKey string, include, exclude []string) bool {
+	for _, pattern := range exclude {
+		if ok, _ := path.Match(pattern, subKey); ok {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, pattern := range include {
+		if ok, _ := path.Match(pattern, subKey); ok {
+			return true
+		}
+	}
+	return false
+}
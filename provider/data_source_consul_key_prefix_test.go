@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"testing"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataConsulKeyPrefix_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	for k, v := range map[string]string{
+		"cfg/a":        "1",
+		"cfg/b/c":      "2",
+		"cfg/b/d/e":    "3",
+		"cfg/skip.tmp": "4",
+		"other/f":      "5",
+	} {
+		consul.kv[k] = &consulapi.KVPair{Key: k, Value: []byte(v), ModifyIndex: 1}
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulKeyPrefixConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.all", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.all", "keys.#", "4"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.all", "keys.0", "a"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.all", "keys.2", "b/d/e"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.all", "subkeys.%", "4"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.all", "subkeys.b/d/e", "3"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.all", "subkeys_base64.%", "4"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.all", "subkeys_base64.a", "MQ=="),
+
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.shallow", "keys.#", "3"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.shallow", "keys.0", "a"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.shallow", "keys.1", "b/c"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.shallow", "keys.2", "b/d/"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.shallow", "subkeys.%", "2"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.shallow", "subkeys.b/c", "2"),
+
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.names", "keys.#", "1"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.names", "keys.0", "b/c"),
+					resource.TestCheckResourceAttr("data.consulclient_key_prefix.names", "subkeys.%", "0"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataConsulKeyPrefixConfig = `
+data "consulclient_key_prefix" "all" {
+	path_prefix = "cfg/"
+}
+
+data "consulclient_key_prefix" "shallow" {
+	path_prefix = "cfg/"
+	depth       = 2
+	exclude     = ["*.tmp"]
+}
+
+data "consulclient_key_prefix" "names" {
+	path_prefix = "cfg/"
+	keys_only   = true
+	include     = ["b/*"]
+}
+`
@@ -4,6 +4,8 @@
 package provider
 
 import (
+	"strconv"
+
 	"github.com/hashicorp/terraform/helper/schema"
 )
 
@@ -51,10 +53,9 @@ func dataSourceConsulKeys() *schema.Resource {
 				ForceNew: true,
 			},
 
-			"token": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
 
 			"key": {
 				Type:     schema.TypeSet,
@@ -75,6 +76,14 @@ func dataSourceConsulKeys() *schema.Resource {
 							Type:     schema.TypeString,
 							Optional: true,
 						},
+
+						// sensitive reads the value of the key into
+						// sensitive_var rather than var.
+						"sensitive": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
 					},
 				},
 			},
@@ -83,6 +92,35 @@ func dataSourceConsulKeys() *schema.Resource {
 				Type:     schema.TypeMap,
 				Computed: true,
 			},
+
+			// var_base64 holds all the values of var base64-encoded, text
+			// or not, so that the values that are not UTF-8 text can be
+			// read as they are.
+			"var_base64": {
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
+
+			// sensitive_var and sensitive_var_base64 hold the values of the
+			// keys marked sensitive, which are hidden from the output.
+			"sensitive_var": {
+				Type:      schema.TypeMap,
+				Computed:  true,
+				Sensitive: true,
+			},
+
+			"sensitive_var_base64": {
+				Type:      schema.TypeMap,
+				Computed:  true,
+				Sensitive: true,
+			},
+
+			// var_flags holds the flags of the keys of var, as decimal
+			// strings, or "0" for the keys that do not exist.
+			"var_flags": {
+				Type:     schema.TypeMap,
+				Computed: true,
+			},
 		},
 	}
 }
@@ -98,8 +136,8 @@ func dataSourceConsulKeysRead(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 	kv := client.KV()
-	token := d.Get("token").(string)
-	dc, err := getDC(d, client)
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
@@ -107,6 +145,10 @@ func dataSourceConsulKeysRead(d *schema.ResourceData, meta interface{}) error {
 	keyClient := newKeyClient(kv, dc, token)
 
 	vars := make(map[string]string)
+	varsBase64 := make(map[string]string)
+	varsFlags := make(map[string]string)
+	sensitiveVars := make(map[string]string)
+	sensitiveVarsBase64 := make(map[string]string)
 
 	keys := d.Get("key").(*schema.Set).List()
 	for _, raw := range keys {
@@ -121,12 +163,31 @@ func dataSourceConsulKeysRead(d *schema.ResourceData, meta interface{}) error {
 		}
 
 		value = attributeValue(sub, value)
+		varsFlags[key] = strconv.FormatUint(keyClient.flags[path], 10)
+		if sensitive, _ := sub["sensitive"].(bool); sensitive {
+			sensitiveVars[key] = value
+			sensitiveVarsBase64[key] = encodeBase64Value(value)
+			continue
+		}
 		vars[key] = value
+		varsBase64[key] = encodeBase64Value(value)
 	}
 
 	if err := d.Set("var", vars); err != nil {
 		return err
 	}
+	if err := d.Set("var_base64", varsBase64); err != nil {
+		return err
+	}
+	if err := d.Set("var_flags", varsFlags); err != nil {
+		return err
+	}
+	if err := d.Set("sensitive_var", sensitiveVars); err != nil {
+		return err
+	}
+	if err := d.Set("sensitive_var_base64", sensitiveVarsBase64); err != nil {
+		return err
+	}
 
 	// Store the datacenter on this resource, which can be helpful for reference
 	// in case it was read from the provider
@@ -0,0 +1,246 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccDataConsulKeys_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulKeysConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "var.read", "written"),
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "var.missing", "default"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataConsulKeys_base64(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulKeysConfig_base64,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "var_base64.binary", "AAH/"),
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "var_base64.missing", "ZGVmYXVsdA=="),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataConsulKeys_flags(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulKeysConfig_flags,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "var.flagged", "value"),
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "var_flags.flagged", "42"),
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "var_flags.missing", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataConsulKeys_sensitive(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccDataConsulKeysConfig_sensitive,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "var.%", "1"),
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "var.user", "admin"),
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "sensitive_var.%", "1"),
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "sensitive_var.password", "hunter2"),
+					resource.TestCheckResourceAttr("data.consulclient_keys.read", "sensitive_var_base64.password", "aHVudGVyMg=="),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataConsulKeys_multipleEndpoints(t *testing.T) {
+	primary := newFakeConsul("dc1")
+	defer primary.Close()
+	secondary := newFakeConsul("dc2")
+	defer secondary.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(primary) + fmt.Sprintf(testAccDataConsulKeysConfig_host, secondary.Address()),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulclient_keys.primary", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("data.consulclient_keys.primary", "var.read", "primary"),
+					resource.TestCheckResourceAttr("data.consulclient_keys.secondary", "datacenter", "dc2"),
+					resource.TestCheckResourceAttr("data.consulclient_keys.secondary", "var.read", "secondary"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataConsulKeysConfig = `
+resource "consulclient_keys" "write" {
+	key {
+		path  = "test/data"
+		value = "written"
+	}
+}
+
+data "consulclient_keys" "read" {
+	datacenter = "${consulclient_keys.write.datacenter}"
+
+	key {
+		name = "read"
+		path = "test/data"
+	}
+
+	key {
+		name    = "missing"
+		path    = "test/missing"
+		default = "default"
+	}
+}
+`
+
+const testAccDataConsulKeysConfig_host = `
+resource "consulclient_keys" "primary" {
+	key {
+		path  = "test/data"
+		value = "primary"
+	}
+}
+
+resource "consulclient_keys" "secondary" {
+	host = "%[1]s"
+
+	key {
+		path  = "test/data"
+		value = "secondary"
+	}
+}
+
+data "consulclient_keys" "primary" {
+	datacenter = "${consulclient_keys.primary.datacenter}"
+
+	key {
+		name = "read"
+		path = "test/data"
+	}
+}
+
+data "consulclient_keys" "secondary" {
+	host       = "%[1]s"
+	datacenter = "${consulclient_keys.secondary.datacenter}"
+
+	key {
+		name = "read"
+		path = "test/data"
+	}
+}
+`
+
+const testAccDataConsulKeysConfig_base64 = `
+resource "consulclient_keys" "write" {
+	key {
+		path         = "test/binary"
+		value_base64 = "AAH/"
+	}
+}
+
+data "consulclient_keys" "read" {
+	datacenter = "${consulclient_keys.write.datacenter}"
+
+	key {
+		name = "binary"
+		path = "test/binary"
+	}
+
+	key {
+		name    = "missing"
+		path    = "test/missing"
+		default = "default"
+	}
+}
+`
+
+const testAccDataConsulKeysConfig_flags = `
+resource "consulclient_keys" "write" {
+	key {
+		path  = "test/flagged"
+		value = "value"
+		flags = 42
+	}
+}
+
+data "consulclient_keys" "read" {
+	datacenter = "${consulclient_keys.write.datacenter}"
+
+	key {
+		name = "flagged"
+		path = "test/flagged"
+	}
+
+	key {
+		name    = "missing"
+		path    = "test/missing"
+		default = "default"
+	}
+}
+`
+
+const testAccDataConsulKeysConfig_sensitive = `
+resource "consulclient_keys" "write" {
+	key {
+		path  = "test/user"
+		value = "admin"
+	}
+
+	key {
+		path            = "test/password"
+		sensitive_value = "hunter2"
+	}
+}
+
+data "consulclient_keys" "read" {
+	datacenter = "${consulclient_keys.write.datacenter}"
+
+	key {
+		name = "user"
+		path = "test/user"
+	}
+
+	key {
+		name      = "password"
+		path      = "test/password"
+		sensitive = true
+	}
+}
+`
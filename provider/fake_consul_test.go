@@ -0,0 +1,1717 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// fakeConsul is an in-process stand-in for the subset of the Consul HTTP API
+// used by the provider. Each instance behaves as a single agent of a
+// single-node cluster, so tests can point different resources at different
+// instances through their host attribute.
+type fakeConsul struct {
+	server *httptest.Server
+
+	datacenter string
+	nodeName   string
+
+	lock    sync.Mutex
+	index   uint64
+	kv      map[string]*consulapi.KVPair
+	nodes   map[string]*fakeConsulNode
+	agent   map[string]*consulapi.AgentService
+	checks  map[string]*consulapi.AgentCheck
+	acls    map[string]*consulapi.ACLEntry
+	queries map[string]*consulapi.PreparedQueryDefinition
+
+	aclPolicies map[string]*consulapi.ACLPolicy
+	aclTokens   map[string]*consulapi.ACLToken
+	aclRoles    map[string]*consulapi.ACLRole
+
+	// aclResetIndex is the reset index of the bootstrapped ACL system, or
+	// 0 before it is bootstrapped. aclResetFile is the path of the
+	// bootstrap reset file, if any.
+	aclResetIndex uint64
+	aclResetFile  string
+
+	aclAuthMethods  map[string]*consulapi.ACLAuthMethod
+	aclBindingRules map[string]*consulapi.ACLBindingRule
+
+	// aclLoginIdentities are the identities verified by the auth methods,
+	// by bearer token.
+	aclLoginIdentities map[string]map[string]string
+
+	// aclReplication is the status of the replication of the ACLs, which
+	// is disabled when nil.
+	aclReplication *consulapi.ACLReplicationStatus
+
+	sessions map[string]*consulapi.SessionEntry
+	renewals map[string]int
+	txns     int
+
+	// tokens are the ACL tokens the requests were sent with.
+	tokens map[string]bool
+}
+
+// fakeConsulNode is a node registered in the catalog of a fakeConsul.
+type fakeConsulNode struct {
+	node     *consulapi.Node
+	services map[string]*consulapi.AgentService
+	checks   map[string]*consulapi.HealthCheck
+}
+
+// fakeConsulHandler handles the requests whose path starts with its
+// prefix. rest is the part of the path following the prefix.
+type fakeConsulHandler func(w http.ResponseWriter, r *http.Request, rest string) (interface{}, int)
+
+// newFakeConsul starts a new fakeConsul for the datacenter dc. Callers
+// must Close it once done.
+func newFakeConsul(dc string) *fakeConsul {
+	c := &fakeConsul{
+		datacenter: dc,
+		nodeName:   "agent-" + dc,
+		kv:         make(map[string]*consulapi.KVPair),
+		nodes:      make(map[string]*fakeConsulNode),
+		agent:      make(map[string]*consulapi.AgentService),
+		checks:     make(map[string]*consulapi.AgentCheck),
+		acls:       make(map[string]*consulapi.ACLEntry),
+		queries:    make(map[string]*consulapi.PreparedQueryDefinition),
+
+		aclPolicies: make(map[string]*consulapi.ACLPolicy),
+		aclTokens:   make(map[string]*consulapi.ACLToken),
+		aclRoles:    make(map[string]*consulapi.ACLRole),
+
+		aclAuthMethods:     make(map[string]*consulapi.ACLAuthMethod),
+		aclBindingRules:    make(map[string]*consulapi.ACLBindingRule),
+		aclLoginIdentities: make(map[string]map[string]string),
+
+		sessions: make(map[string]*consulapi.SessionEntry),
+		renewals: make(map[string]int),
+		tokens:   make(map[string]bool),
+	}
+
+	c.nodes[c.nodeName] = &fakeConsulNode{
+		node: &consulapi.Node{
+			ID:         fmt.Sprintf("00000000-0000-0000-0000-%012d", len(dc)),
+			Node:       c.nodeName,
+			Address:    "127.0.0.1",
+			Datacenter: dc,
+		},
+		services: make(map[string]*consulapi.AgentService),
+		checks:   make(map[string]*consulapi.HealthCheck),
+	}
+
+	c.server = httptest.NewServer(c)
+	return c
+}
+
+// Close shuts down the fake agent.
+func (c *fakeConsul) Close() {
+	c.server.Close()
+}
+
+// Address returns the host:port the fake agent listens on.
+func (c *fakeConsul) Address() string {
+	return strings.TrimPrefix(c.server.URL, "http://")
+}
+
+// handlers returns the endpoints served by the fake agent, keyed by path
+// prefix. Longer prefixes take precedence over shorter ones.
+func (c *fakeConsul) handlers() map[string]fakeConsulHandler {
+	return map[string]fakeConsulHandler{
+		"/v1/kv/":                       c.handleKV,
+		"/v1/catalog/register":          c.handleCatalogRegister,
+		"/v1/catalog/deregister":        c.handleCatalogDeregister,
+		"/v1/catalog/nodes":             c.handleCatalogNodes,
+		"/v1/catalog/node/":             c.handleCatalogNode,
+		"/v1/catalog/services":          c.handleCatalogServices,
+		"/v1/catalog/service/":          c.handleCatalogService,
+		"/v1/health/node/":              c.handleHealthNode,
+		"/v1/agent/self":                c.handleAgentSelf,
+		"/v1/agent/services":            c.handleAgentServices,
+		"/v1/agent/checks":              c.handleAgentChecks,
+		"/v1/agent/check/register":      c.handleAgentCheckRegister,
+		"/v1/agent/check/deregister/":   c.handleAgentCheckDeregister,
+		"/v1/agent/check/update/":       c.handleAgentCheckUpdate,
+		"/v1/agent/service/register":    c.handleAgentServiceRegister,
+		"/v1/agent/service/deregister/": c.handleAgentServiceDeregister,
+		"/v1/acl/create":                c.handleACLCreate,
+		"/v1/acl/update":                c.handleACLUpdate,
+		"/v1/acl/destroy/":              c.handleACLDestroy,
+		"/v1/acl/info/":                 c.handleACLInfo,
+		"/v1/acl/bootstrap":             c.handleACLBootstrap,
+		"/v1/acl/auth-method":           c.handleACLAuthMethod,
+		"/v1/acl/binding-rule":          c.handleACLBindingRule,
+		"/v1/acl/login":                 c.handleACLLogin,
+		"/v1/acl/policy":                c.handleACLPolicy,
+		"/v1/acl/policies":              c.handleACLPolicies,
+		"/v1/acl/token":                 c.handleACLToken,
+		"/v1/acl/tokens":                c.handleACLTokens,
+		"/v1/acl/replication":           c.handleACLReplication,
+		"/v1/acl/role":                  c.handleACLRole,
+		"/v1/session/create":            c.handleSessionCreate,
+		"/v1/session/destroy/":          c.handleSessionDestroy,
+		"/v1/session/info/":             c.handleSessionInfo,
+		"/v1/session/renew/":            c.handleSessionRenew,
+		"/v1/txn":                       c.handleTxn,
+		"/v1/query":                     c.handleQuery,
+	}
+}
+
+func (c *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var prefix string
+	var handler fakeConsulHandler
+	for p, h := range c.handlers() {
+		if strings.HasPrefix(r.URL.Path, p) && len(p) > len(prefix) {
+			prefix, handler = p, h
+		}
+	}
+	if handler == nil {
+		http.Error(w, fmt.Sprintf("unsupported endpoint %s %s", r.Method, r.URL.Path), http.StatusNotFound)
+		return
+	}
+
+	if r.Method == "GET" {
+		c.block(r)
+	}
+
+	c.lock.Lock()
+	if token := r.Header.Get("X-Consul-Token"); token != "" {
+		c.tokens[token] = true
+	}
+	body, status := handler(w, r, strings.TrimPrefix(r.URL.Path, prefix))
+	index := c.index
+	c.lock.Unlock()
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(index, 10))
+	w.Header().Set("X-Consul-LastContact", "0")
+	w.Header().Set("X-Consul-KnownLeader", "true")
+
+	// Errors are plain text, except for the ones of transactions.
+	if _, ok := body.(string); ok && status >= 400 {
+		http.Error(w, fmt.Sprint(body), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if body != nil {
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+// nextIndex bumps and returns the raft index of the fake cluster.
+func (c *fakeConsul) nextIndex() uint64 {
+	c.index++
+	return c.index
+}
+
+// block implements blocking queries coarsely: it waits until the index of
+// the whole fake, rather than the one of the queried data, passes the index
+// of the request, or until the request's wait time elapses.
+func (c *fakeConsul) block(r *http.Request) {
+	q := r.URL.Query()
+	index, err := strconv.ParseUint(q.Get("index"), 10, 64)
+	if err != nil || index == 0 {
+		return
+	}
+	wait := 5 * time.Minute
+	if v := q.Get("wait"); v != "" {
+		if wait, err = time.ParseDuration(v); err != nil {
+			return
+		}
+	}
+
+	deadline := time.Now().Add(wait)
+	for time.Now().Before(deadline) {
+		c.lock.Lock()
+		current := c.index
+		c.lock.Unlock()
+		if current > index {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// nextID returns a new unique identifier.
+func (c *fakeConsul) nextID() string {
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", c.nextIndex())
+}
+
+// decode reads the JSON body of r into v.
+func decode(r *http.Request, v interface{}) error {
+	return json.NewDecoder(r.Body).Decode(v)
+}
+
+func methodNotAllowed(r *http.Request) (interface{}, int) {
+	return fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed
+}
+
+func (c *fakeConsul) handleKV(w http.ResponseWriter, r *http.Request, key string) (interface{}, int) {
+	q := r.URL.Query()
+	_, recurse := q["recurse"]
+	_, keysOnly := q["keys"]
+
+	switch r.Method {
+	case "GET":
+		if !recurse && !keysOnly {
+			pair, ok := c.kv[key]
+			if !ok {
+				return "", http.StatusNotFound
+			}
+			return []*consulapi.KVPair{pair}, http.StatusOK
+		}
+
+		var paths []string
+		for k := range c.kv {
+			if strings.HasPrefix(k, key) {
+				paths = append(paths, k)
+			}
+		}
+		sort.Strings(paths)
+
+		if keysOnly {
+			separator := q.Get("separator")
+			keys := []string{}
+			seen := make(map[string]bool)
+			for _, k := range paths {
+				if separator != "" {
+					if i := strings.Index(k[len(key):], separator); i >= 0 {
+						k = k[:len(key)+i+len(separator)]
+					}
+				}
+				if !seen[k] {
+					seen[k] = true
+					keys = append(keys, k)
+				}
+			}
+			if len(keys) == 0 {
+				return "", http.StatusNotFound
+			}
+			return keys, http.StatusOK
+		}
+
+		pairs := make([]*consulapi.KVPair, 0, len(paths))
+		for _, k := range paths {
+			pairs = append(pairs, c.kv[k])
+		}
+		if len(pairs) == 0 {
+			return "", http.StatusNotFound
+		}
+		return pairs, http.StatusOK
+
+	case "PUT":
+		value, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+
+		existing, exists := c.kv[key]
+		var session string
+		if acquire := q.Get("acquire"); acquire != "" {
+			if _, ok := c.sessions[acquire]; !ok {
+				return fmt.Sprintf("invalid session %q", acquire), http.StatusInternalServerError
+			}
+			if exists && existing.Session != "" && existing.Session != acquire {
+				return false, http.StatusOK
+			}
+			session = acquire
+		}
+		if release := q.Get("release"); release != "" {
+			if !exists || existing.Session != release {
+				return false, http.StatusOK
+			}
+		}
+		if exists && session == "" && q.Get("release") == "" {
+			session = existing.Session
+		}
+		if cas := q.Get("cas"); cas != "" {
+			index, err := strconv.ParseUint(cas, 10, 64)
+			if err != nil {
+				return err.Error(), http.StatusBadRequest
+			}
+			if (index == 0 && exists) || (index != 0 && (!exists || existing.ModifyIndex != index)) {
+				return false, http.StatusOK
+			}
+		}
+
+		pair := &consulapi.KVPair{Key: key, Value: value, Session: session}
+		if exists {
+			pair.LockIndex = existing.LockIndex
+		}
+		if session != "" && (!exists || existing.Session != session) {
+			pair.LockIndex++
+		}
+		if flags := q.Get("flags"); flags != "" {
+			if pair.Flags, err = strconv.ParseUint(flags, 10, 64); err != nil {
+				return err.Error(), http.StatusBadRequest
+			}
+		}
+		pair.ModifyIndex = c.nextIndex()
+		pair.CreateIndex = pair.ModifyIndex
+		if exists {
+			pair.CreateIndex = existing.CreateIndex
+		}
+		c.kv[key] = pair
+		return true, http.StatusOK
+
+	case "DELETE":
+		if recurse {
+			for k := range c.kv {
+				if strings.HasPrefix(k, key) {
+					delete(c.kv, k)
+				}
+			}
+			c.nextIndex()
+			return true, http.StatusOK
+		}
+
+		if cas := q.Get("cas"); cas != "" {
+			index, err := strconv.ParseUint(cas, 10, 64)
+			if err != nil {
+				return err.Error(), http.StatusBadRequest
+			}
+			if existing, ok := c.kv[key]; ok && existing.ModifyIndex != index {
+				return false, http.StatusOK
+			}
+		}
+		delete(c.kv, key)
+		c.nextIndex()
+		return true, http.StatusOK
+	}
+
+	return methodNotAllowed(r)
+}
+
+// handleTxn applies the KV operations of a transaction to a copy of the
+// store, which replaces the store only if all of them succeed.
+func (c *fakeConsul) handleTxn(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	var ops consulapi.TxnOps
+	if err := decode(r, &ops); err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	if len(ops) > 64 {
+		return fmt.Sprintf("Transaction contains too many operations (%d > 64)", len(ops)), http.StatusRequestEntityTooLarge
+	}
+	c.txns++
+
+	kv := make(map[string]*consulapi.KVPair, len(c.kv))
+	for k, pair := range c.kv {
+		kv[k] = pair
+	}
+	index := c.index + 1
+
+	var resp consulapi.TxnResponse
+	fail := func(i int, format string, args ...interface{}) {
+		resp.Errors = append(resp.Errors, &consulapi.TxnError{OpIndex: i, What: fmt.Sprintf(format, args...)})
+	}
+	result := func(pair *consulapi.KVPair) {
+		result := *pair
+		result.Value = nil
+		resp.Results = append(resp.Results, &consulapi.TxnResult{KV: &result})
+	}
+
+	for i, op := range ops {
+		if op.KV == nil {
+			fail(i, "unsupported operation")
+			continue
+		}
+		key := op.KV.Key
+		existing, exists := kv[key]
+
+		switch op.KV.Verb {
+		case consulapi.KVSet, consulapi.KVCAS:
+			if op.KV.Verb == consulapi.KVCAS {
+				if (op.KV.Index == 0 && exists) || (op.KV.Index != 0 && (!exists || existing.ModifyIndex != op.KV.Index)) {
+					fail(i, "failed to set key %q, index is stale", key)
+					continue
+				}
+			}
+			pair := &consulapi.KVPair{
+				Key:         key,
+				Value:       op.KV.Value,
+				Flags:       op.KV.Flags,
+				CreateIndex: index,
+				ModifyIndex: index,
+			}
+			if exists {
+				pair.CreateIndex = existing.CreateIndex
+				pair.LockIndex = existing.LockIndex
+				pair.Session = existing.Session
+			}
+			kv[key] = pair
+			result(pair)
+
+		case consulapi.KVDelete:
+			delete(kv, key)
+
+		case consulapi.KVDeleteCAS:
+			if exists && existing.ModifyIndex != op.KV.Index {
+				fail(i, "failed to delete key %q, index is stale", key)
+				continue
+			}
+			delete(kv, key)
+
+		case consulapi.KVDeleteTree:
+			for k := range kv {
+				if strings.HasPrefix(k, key) {
+					delete(kv, k)
+				}
+			}
+
+		case consulapi.KVCheckIndex:
+			if !exists {
+				fail(i, "key %q doesn't exist", key)
+				continue
+			}
+			if existing.ModifyIndex != op.KV.Index {
+				fail(i, "current modify index %d != %d", existing.ModifyIndex, op.KV.Index)
+				continue
+			}
+			result(existing)
+
+		default:
+			fail(i, "unsupported KV verb %q", op.KV.Verb)
+		}
+	}
+
+	if len(resp.Errors) > 0 {
+		return resp, http.StatusConflict
+	}
+	c.kv = kv
+	c.nextIndex()
+	return resp, http.StatusOK
+}
+
+func (c *fakeConsul) handleCatalogRegister(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	var reg consulapi.CatalogRegistration
+	if err := decode(r, &reg); err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	if reg.Node == "" || reg.Address == "" {
+		return "Must provide node and address", http.StatusBadRequest
+	}
+
+	index := c.nextIndex()
+
+	n, ok := c.nodes[reg.Node]
+	if !ok {
+		n = &fakeConsulNode{
+			node: &consulapi.Node{
+				ID:          reg.ID,
+				Node:        reg.Node,
+				CreateIndex: index,
+			},
+			services: make(map[string]*consulapi.AgentService),
+			checks:   make(map[string]*consulapi.HealthCheck),
+		}
+		c.nodes[reg.Node] = n
+	}
+	if !reg.SkipNodeUpdate {
+		n.node.Address = reg.Address
+		n.node.Datacenter = c.datacenter
+		n.node.TaggedAddresses = reg.TaggedAddresses
+		n.node.Meta = reg.NodeMeta
+		n.node.ModifyIndex = index
+	}
+
+	if reg.Service != nil {
+		service := *reg.Service
+		if service.ID == "" {
+			service.ID = service.Service
+		}
+		service.CreateIndex = index
+		if existing, ok := n.services[service.ID]; ok {
+			service.CreateIndex = existing.CreateIndex
+		}
+		service.ModifyIndex = index
+		n.services[service.ID] = &service
+	}
+
+	for _, check := range reg.Checks {
+		check := *check
+		if check.CheckID == "" {
+			check.CheckID = check.Name
+		}
+		if check.Status == "" {
+			check.Status = consulapi.HealthCritical
+		}
+		if check.ServiceID != "" {
+			service, ok := n.services[check.ServiceID]
+			if !ok {
+				return fmt.Sprintf("Missing service %q for check %q", check.ServiceID, check.CheckID), http.StatusInternalServerError
+			}
+			check.ServiceName = service.Service
+		}
+		check.Node = n.node.Node
+		check.ModifyIndex = index
+		n.checks[check.CheckID] = &check
+	}
+
+	return true, http.StatusOK
+}
+
+func (c *fakeConsul) handleCatalogDeregister(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	var dereg consulapi.CatalogDeregistration
+	if err := decode(r, &dereg); err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+
+	c.nextIndex()
+
+	n, ok := c.nodes[dereg.Node]
+	if !ok {
+		return true, http.StatusOK
+	}
+
+	switch {
+	case dereg.ServiceID != "":
+		delete(n.services, dereg.ServiceID)
+		for id, check := range n.checks {
+			if check.ServiceID == dereg.ServiceID {
+				delete(n.checks, id)
+			}
+		}
+	case dereg.CheckID != "":
+		delete(n.checks, dereg.CheckID)
+	default:
+		delete(c.nodes, dereg.Node)
+	}
+
+	return true, http.StatusOK
+}
+
+func (c *fakeConsul) handleCatalogNodes(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+
+	filters := r.URL.Query()["node-meta"]
+
+	names := make([]string, 0, len(c.nodes))
+	for name := range c.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	nodes := make([]*consulapi.Node, 0, len(names))
+NODES:
+	for _, name := range names {
+		node := c.nodes[name].node
+		for _, filter := range filters {
+			kv := strings.SplitN(filter, ":", 2)
+			if len(kv) != 2 || node.Meta[kv[0]] != kv[1] {
+				continue NODES
+			}
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, http.StatusOK
+}
+
+func (c *fakeConsul) handleCatalogNode(w http.ResponseWriter, r *http.Request, name string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+
+	n, ok := c.nodes[name]
+	if !ok {
+		return (*consulapi.CatalogNode)(nil), http.StatusOK
+	}
+
+	return &consulapi.CatalogNode{
+		Node:     n.node,
+		Services: n.services,
+	}, http.StatusOK
+}
+
+func (c *fakeConsul) handleCatalogServices(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+
+	services := map[string][]string{"consul": {}}
+	for _, n := range c.nodes {
+		for _, service := range n.services {
+			tags := services[service.Service]
+			for _, tag := range service.Tags {
+				if !containsString(tags, tag) {
+					tags = append(tags, tag)
+				}
+			}
+			if tags == nil {
+				tags = []string{}
+			}
+			services[service.Service] = tags
+		}
+	}
+
+	return services, http.StatusOK
+}
+
+func (c *fakeConsul) handleCatalogService(w http.ResponseWriter, r *http.Request, name string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+
+	tag := r.URL.Query().Get("tag")
+
+	names := make([]string, 0, len(c.nodes))
+	for nodeName := range c.nodes {
+		names = append(names, nodeName)
+	}
+	sort.Strings(names)
+
+	services := []*consulapi.CatalogService{}
+	for _, nodeName := range names {
+		n := c.nodes[nodeName]
+		for _, service := range n.services {
+			if service.Service != name || (tag != "" && !containsString(service.Tags, tag)) {
+				continue
+			}
+			services = append(services, &consulapi.CatalogService{
+				ID:                       n.node.ID,
+				Node:                     n.node.Node,
+				Address:                  n.node.Address,
+				Datacenter:               c.datacenter,
+				TaggedAddresses:          n.node.TaggedAddresses,
+				NodeMeta:                 n.node.Meta,
+				ServiceID:                service.ID,
+				ServiceName:              service.Service,
+				ServiceAddress:           service.Address,
+				ServiceTags:              service.Tags,
+				ServiceMeta:              service.Meta,
+				ServicePort:              service.Port,
+				ServiceEnableTagOverride: service.EnableTagOverride,
+				CreateIndex:              service.CreateIndex,
+				ModifyIndex:              service.ModifyIndex,
+			})
+		}
+	}
+
+	return services, http.StatusOK
+}
+
+func (c *fakeConsul) handleHealthNode(w http.ResponseWriter, r *http.Request, name string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+
+	checks := consulapi.HealthChecks{}
+	n, ok := c.nodes[name]
+	if !ok {
+		return checks, http.StatusOK
+	}
+
+	ids := make([]string, 0, len(n.checks))
+	for id := range n.checks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		checks = append(checks, n.checks[id])
+	}
+
+	return checks, http.StatusOK
+}
+
+func (c *fakeConsul) handleAgentSelf(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+
+	return map[string]map[string]interface{}{
+		"Config": {
+			"Datacenter": c.datacenter,
+			"NodeName":   c.nodeName,
+			"Server":     true,
+			"Version":    "1.8.0",
+		},
+		"Member": {
+			"Name": c.nodeName,
+			"Addr": "127.0.0.1",
+		},
+	}, http.StatusOK
+}
+
+func (c *fakeConsul) handleAgentServices(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+
+	return c.agent, http.StatusOK
+}
+
+func (c *fakeConsul) handleAgentServiceRegister(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	var reg consulapi.AgentServiceRegistration
+	if err := decode(r, &reg); err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	if reg.Name == "" {
+		return "Missing service name", http.StatusBadRequest
+	}
+
+	service := &consulapi.AgentService{
+		ID:                reg.ID,
+		Service:           reg.Name,
+		Tags:              reg.Tags,
+		Meta:              reg.Meta,
+		Port:              reg.Port,
+		Address:           reg.Address,
+		EnableTagOverride: reg.EnableTagOverride,
+	}
+	if service.ID == "" {
+		service.ID = service.Service
+	}
+	if service.Tags == nil {
+		service.Tags = []string{}
+	}
+	service.ModifyIndex = c.nextIndex()
+
+	checks := reg.Checks
+	if reg.Check != nil {
+		checks = append(consulapi.AgentServiceChecks{reg.Check}, checks...)
+	}
+	for i, check := range checks {
+		id := check.CheckID
+		if id == "" {
+			id = fmt.Sprintf("service:%s", service.ID)
+			if len(checks) > 1 {
+				id = fmt.Sprintf("service:%s:%d", service.ID, i+1)
+			}
+		}
+		c.checks[id] = fakeAgentCheck(c.nodeName, id, service, check)
+	}
+
+	// Services registered with the agent are synced to the catalog as
+	// services of the agent's node.
+	c.agent[service.ID] = service
+	c.nodes[c.nodeName].services[service.ID] = service
+
+	return nil, http.StatusOK
+}
+
+func (c *fakeConsul) handleAgentChecks(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+
+	return c.checks, http.StatusOK
+}
+
+func (c *fakeConsul) handleAgentCheckRegister(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	var reg consulapi.AgentCheckRegistration
+	if err := decode(r, &reg); err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	if reg.Name == "" {
+		return "Missing check name", http.StatusBadRequest
+	}
+
+	var service *consulapi.AgentService
+	if reg.ServiceID != "" {
+		var ok bool
+		if service, ok = c.agent[reg.ServiceID]; !ok {
+			return fmt.Sprintf("ServiceID %q does not exist", reg.ServiceID), http.StatusInternalServerError
+		}
+	}
+
+	id := reg.ID
+	if id == "" {
+		id = reg.Name
+	}
+	check := reg.AgentServiceCheck
+	check.Name = reg.Name
+	check.Notes = reg.Notes
+	c.checks[id] = fakeAgentCheck(c.nodeName, id, service, &check)
+	c.nextIndex()
+
+	return nil, http.StatusOK
+}
+
+func (c *fakeConsul) handleAgentCheckDeregister(w http.ResponseWriter, r *http.Request, id string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	if _, ok := c.checks[id]; !ok {
+		return fmt.Sprintf("Unknown check %q", id), http.StatusInternalServerError
+	}
+	delete(c.checks, id)
+	c.nextIndex()
+
+	return nil, http.StatusOK
+}
+
+func (c *fakeConsul) handleAgentCheckUpdate(w http.ResponseWriter, r *http.Request, id string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	check, ok := c.checks[id]
+	if !ok {
+		return fmt.Sprintf("Unknown check %q", id), http.StatusInternalServerError
+	}
+	if check.Type != "ttl" {
+		return fmt.Sprintf("Check %q is not a TTL check", id), http.StatusInternalServerError
+	}
+
+	var update struct {
+		Status string
+		Output string
+	}
+	if err := decode(r, &update); err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	check.Status = update.Status
+	check.Output = update.Output
+	c.nextIndex()
+
+	return nil, http.StatusOK
+}
+
+// fakeAgentCheck returns the check with the given ID that the agent reports
+// for the definition check of service, which may be nil.
+func fakeAgentCheck(node, id string, service *consulapi.AgentService, check *consulapi.AgentServiceCheck) *consulapi.AgentCheck {
+	agentCheck := &consulapi.AgentCheck{
+		Node:    node,
+		CheckID: id,
+		Name:    check.Name,
+		Status:  check.Status,
+		Notes:   check.Notes,
+		Definition: consulapi.HealthCheckDefinition{
+			HTTP:          check.HTTP,
+			Header:        check.Header,
+			Method:        check.Method,
+			TLSSkipVerify: check.TLSSkipVerify,
+			TCP:           check.TCP,
+		},
+	}
+	if agentCheck.Status == "" {
+		agentCheck.Status = consulapi.HealthCritical
+	}
+	if service != nil {
+		agentCheck.ServiceID = service.ID
+		agentCheck.ServiceName = service.Service
+	}
+
+	switch {
+	case check.HTTP != "":
+		agentCheck.Type = "http"
+	case check.TCP != "":
+		agentCheck.Type = "tcp"
+	case check.TTL != "":
+		agentCheck.Type = "ttl"
+	case len(check.Args) > 0:
+		agentCheck.Type = "script"
+	case check.GRPC != "":
+		agentCheck.Type = "grpc"
+	case check.DockerContainerID != "":
+		agentCheck.Type = "docker"
+	}
+
+	agentCheck.Definition.IntervalDuration, _ = time.ParseDuration(check.Interval)
+	agentCheck.Definition.TimeoutDuration, _ = time.ParseDuration(check.Timeout)
+	agentCheck.Definition.DeregisterCriticalServiceAfterDuration, _ = time.ParseDuration(check.DeregisterCriticalServiceAfter)
+	agentCheck.Definition.Interval = consulapi.ReadableDuration(agentCheck.Definition.IntervalDuration)
+	agentCheck.Definition.Timeout = consulapi.ReadableDuration(agentCheck.Definition.TimeoutDuration)
+	agentCheck.Definition.DeregisterCriticalServiceAfter = consulapi.ReadableDuration(agentCheck.Definition.DeregisterCriticalServiceAfterDuration)
+
+	return agentCheck
+}
+
+func (c *fakeConsul) handleAgentServiceDeregister(w http.ResponseWriter, r *http.Request, id string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	if _, ok := c.agent[id]; !ok {
+		return fmt.Sprintf("Unknown service %q", id), http.StatusNotFound
+	}
+	delete(c.agent, id)
+	delete(c.nodes[c.nodeName].services, id)
+	for checkID, check := range c.checks {
+		if check.ServiceID == id {
+			delete(c.checks, checkID)
+		}
+	}
+	c.nextIndex()
+
+	return nil, http.StatusOK
+}
+
+func (c *fakeConsul) handleACLCreate(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	var acl consulapi.ACLEntry
+	if err := decode(r, &acl); err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	if acl.ID == "" {
+		acl.ID = c.nextID()
+	}
+	if acl.Type == "" {
+		acl.Type = consulapi.ACLClientType
+	}
+	acl.CreateIndex = c.nextIndex()
+	acl.ModifyIndex = acl.CreateIndex
+	c.acls[acl.ID] = &acl
+
+	return map[string]string{"ID": acl.ID}, http.StatusOK
+}
+
+func (c *fakeConsul) handleACLUpdate(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	var acl consulapi.ACLEntry
+	if err := decode(r, &acl); err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	if acl.ID == "" {
+		return "ACL ID must be set", http.StatusBadRequest
+	}
+	if acl.Type == "" {
+		acl.Type = consulapi.ACLClientType
+	}
+	acl.ModifyIndex = c.nextIndex()
+	acl.CreateIndex = acl.ModifyIndex
+	if existing, ok := c.acls[acl.ID]; ok {
+		acl.CreateIndex = existing.CreateIndex
+	}
+	c.acls[acl.ID] = &acl
+
+	return map[string]string{"ID": acl.ID}, http.StatusOK
+}
+
+func (c *fakeConsul) handleACLDestroy(w http.ResponseWriter, r *http.Request, id string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	delete(c.acls, id)
+	c.nextIndex()
+
+	return true, http.StatusOK
+}
+
+func (c *fakeConsul) handleACLInfo(w http.ResponseWriter, r *http.Request, id string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+
+	entries := []*consulapi.ACLEntry{}
+	if acl, ok := c.acls[id]; ok {
+		entries = append(entries, acl)
+	}
+
+	return entries, http.StatusOK
+}
+
+// aclNotFound is the error of Consul when reading a token or a policy that
+// does not exist.
+func aclNotFound() (interface{}, int) {
+	return "ACL not found", http.StatusForbidden
+}
+
+func (c *fakeConsul) handleACLPolicy(w http.ResponseWriter, r *http.Request, rest string) (interface{}, int) {
+	id := strings.TrimPrefix(rest, "/")
+
+	switch r.Method {
+	case "GET":
+		if strings.HasPrefix(id, "name/") {
+			for _, policy := range c.aclPolicies {
+				if policy.Name == strings.TrimPrefix(id, "name/") {
+					return policy, http.StatusOK
+				}
+			}
+			return aclNotFound()
+		}
+		policy, ok := c.aclPolicies[id]
+		if !ok {
+			return aclNotFound()
+		}
+		return policy, http.StatusOK
+	case "PUT":
+		var policy consulapi.ACLPolicy
+		if err := decode(r, &policy); err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+		for _, other := range c.aclPolicies {
+			if other.Name == policy.Name && other.ID != id {
+				return fmt.Sprintf("Invalid Policy: A Policy with Name %q already exists", policy.Name), http.StatusBadRequest
+			}
+		}
+		if id == "" {
+			policy.ID = c.nextID()
+			policy.CreateIndex = c.nextIndex()
+		} else {
+			existing, ok := c.aclPolicies[id]
+			if !ok {
+				return aclNotFound()
+			}
+			policy.ID = id
+			policy.CreateIndex = existing.CreateIndex
+		}
+		policy.ModifyIndex = c.nextIndex()
+		c.aclPolicies[policy.ID] = &policy
+		return &policy, http.StatusOK
+	case "DELETE":
+		delete(c.aclPolicies, id)
+		c.nextIndex()
+		return true, http.StatusOK
+	}
+	return methodNotAllowed(r)
+}
+
+func (c *fakeConsul) handleACLPolicies(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+	ids := make([]string, 0, len(c.aclPolicies))
+	for id := range c.aclPolicies {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	policies := make([]*consulapi.ACLPolicyListEntry, 0, len(ids))
+	for _, id := range ids {
+		policy := c.aclPolicies[id]
+		policies = append(policies, &consulapi.ACLPolicyListEntry{
+			ID:          policy.ID,
+			Name:        policy.Name,
+			Description: policy.Description,
+			Datacenters: policy.Datacenters,
+			CreateIndex: policy.CreateIndex,
+			ModifyIndex: policy.ModifyIndex,
+		})
+	}
+	return policies, http.StatusOK
+}
+
+// resolveACLLinks sets the IDs of the links given by name, and the names of
+// the links given by ID, to the ones of the policies, or of the roles if
+// roles is true.
+func (c *fakeConsul) resolveACLLinks(links []*consulapi.ACLLink, roles bool) error {
+	for _, link := range links {
+		var id, name string
+		if roles {
+			for _, role := range c.aclRoles {
+				if role.ID == link.ID || role.Name == link.Name {
+					id, name = role.ID, role.Name
+				}
+			}
+		} else {
+			for _, policy := range c.aclPolicies {
+				if policy.ID == link.ID || policy.Name == link.Name {
+					id, name = policy.ID, policy.Name
+				}
+			}
+		}
+		if id == "" {
+			return fmt.Errorf("cannot find policy or role %q", link.ID+link.Name)
+		}
+		link.ID, link.Name = id, name
+	}
+	return nil
+}
+
+func (c *fakeConsul) handleACLToken(w http.ResponseWriter, r *http.Request, rest string) (interface{}, int) {
+	id := strings.TrimPrefix(rest, "/")
+
+	switch r.Method {
+	case "GET":
+		token, ok := c.aclTokens[id]
+		if !ok {
+			return aclNotFound()
+		}
+		return c.readACLToken(token), http.StatusOK
+	case "PUT":
+		var token consulapi.ACLToken
+		if err := decode(r, &token); err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+		if err := c.resolveACLLinks(token.Policies, false); err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+		if err := c.resolveACLLinks(token.Roles, true); err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+		if id == "" {
+			if token.AccessorID == "" {
+				token.AccessorID = c.nextID()
+			} else if _, exists := c.aclTokens[token.AccessorID]; exists {
+				return "Invalid Token: AccessorID is already in use", http.StatusBadRequest
+			}
+			if token.SecretID == "" {
+				token.SecretID = c.nextID()
+			}
+			token.CreateTime = time.Now().UTC()
+			if token.ExpirationTTL != 0 {
+				expirationTime := token.CreateTime.Add(token.ExpirationTTL)
+				token.ExpirationTime = &expirationTime
+				token.ExpirationTTL = 0
+			}
+			token.CreateIndex = c.nextIndex()
+		} else {
+			existing, ok := c.aclTokens[id]
+			if !ok {
+				return aclNotFound()
+			}
+			if token.Local != existing.Local {
+				return "Cannot toggle local mode of " + id, http.StatusBadRequest
+			}
+			if token.ExpirationTTL != 0 {
+				return "Cannot change expiration time of " + id, http.StatusBadRequest
+			}
+			token.AccessorID = id
+			token.SecretID = existing.SecretID
+			token.ExpirationTime = existing.ExpirationTime
+			token.CreateTime = existing.CreateTime
+			token.CreateIndex = existing.CreateIndex
+		}
+		token.ModifyIndex = c.nextIndex()
+		c.aclTokens[token.AccessorID] = &token
+		return &token, http.StatusOK
+	case "DELETE":
+		delete(c.aclTokens, id)
+		c.nextIndex()
+		return true, http.StatusOK
+	}
+	return methodNotAllowed(r)
+}
+
+// readACLToken returns token as read from the API: links to deleted
+// policies and roles are dropped, and renamed ones show their current name.
+func (c *fakeConsul) readACLToken(token *consulapi.ACLToken) *consulapi.ACLToken {
+	read := *token
+	read.Policies, read.Roles = nil, nil
+	for _, link := range token.Policies {
+		if policy, ok := c.aclPolicies[link.ID]; ok {
+			read.Policies = append(read.Policies, &consulapi.ACLLink{ID: policy.ID, Name: policy.Name})
+		}
+	}
+	for _, link := range token.Roles {
+		if role, ok := c.aclRoles[link.ID]; ok {
+			read.Roles = append(read.Roles, &consulapi.ACLLink{ID: role.ID, Name: role.Name})
+		}
+	}
+	return &read
+}
+
+func (c *fakeConsul) handleACLTokens(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+	ids := make([]string, 0, len(c.aclTokens))
+	for id := range c.aclTokens {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	tokens := make([]*consulapi.ACLTokenListEntry, 0, len(ids))
+	for _, id := range ids {
+		token := c.readACLToken(c.aclTokens[id])
+		tokens = append(tokens, &consulapi.ACLTokenListEntry{
+			CreateIndex:       token.CreateIndex,
+			ModifyIndex:       token.ModifyIndex,
+			AccessorID:        token.AccessorID,
+			Description:       token.Description,
+			Policies:          token.Policies,
+			Roles:             token.Roles,
+			ServiceIdentities: token.ServiceIdentities,
+			Local:             token.Local,
+			AuthMethod:        token.AuthMethod,
+			ExpirationTime:    token.ExpirationTime,
+			CreateTime:        token.CreateTime,
+		})
+	}
+	return tokens, http.StatusOK
+}
+
+func (c *fakeConsul) handleACLRole(w http.ResponseWriter, r *http.Request, rest string) (interface{}, int) {
+	id := strings.TrimPrefix(rest, "/")
+
+	switch r.Method {
+	case "GET":
+		role, ok := c.aclRoles[id]
+		if !ok {
+			return "", http.StatusNotFound
+		}
+		read := *role
+		read.Policies = nil
+		for _, link := range role.Policies {
+			if policy, ok := c.aclPolicies[link.ID]; ok {
+				read.Policies = append(read.Policies, &consulapi.ACLLink{ID: policy.ID, Name: policy.Name})
+			}
+		}
+		return &read, http.StatusOK
+	case "PUT":
+		var role consulapi.ACLRole
+		if err := decode(r, &role); err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+		if err := c.resolveACLLinks(role.Policies, false); err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+		for _, other := range c.aclRoles {
+			if other.Name == role.Name && other.ID != id {
+				return fmt.Sprintf("Invalid Role: A Role with Name %q already exists", role.Name), http.StatusBadRequest
+			}
+		}
+		if id == "" {
+			role.ID = c.nextID()
+			role.CreateIndex = c.nextIndex()
+		} else {
+			existing, ok := c.aclRoles[id]
+			if !ok {
+				return "", http.StatusNotFound
+			}
+			role.ID = id
+			role.CreateIndex = existing.CreateIndex
+		}
+		role.ModifyIndex = c.nextIndex()
+		c.aclRoles[role.ID] = &role
+		return &role, http.StatusOK
+	case "DELETE":
+		delete(c.aclRoles, id)
+		c.nextIndex()
+		return true, http.StatusOK
+	}
+	return methodNotAllowed(r)
+}
+
+func (c *fakeConsul) handleACLBootstrap(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	// As in Consul, an ACL system can only be bootstrapped again once its
+	// reset index has been written to the reset file.
+	if c.aclResetIndex != 0 {
+		b, err := ioutil.ReadFile(c.aclResetFile)
+		if c.aclResetFile == "" || err != nil || strings.TrimSpace(string(b)) != strconv.FormatUint(c.aclResetIndex, 10) {
+			return fmt.Sprintf("Permission denied: ACL bootstrap no longer allowed (reset index: %d)", c.aclResetIndex), http.StatusForbidden
+		}
+	}
+
+	policy := &consulapi.ACLPolicy{
+		ID:          "00000000-0000-0000-0000-000000000001",
+		Name:        "global-management",
+		Description: "Builtin Policy that grants unlimited access",
+		Rules:       `acl = "write"`,
+	}
+	if _, ok := c.aclPolicies[policy.ID]; !ok {
+		policy.CreateIndex = c.nextIndex()
+		policy.ModifyIndex = policy.CreateIndex
+		c.aclPolicies[policy.ID] = policy
+	}
+
+	token := &consulapi.ACLToken{
+		AccessorID:  c.nextID(),
+		SecretID:    c.nextID(),
+		Description: "Bootstrap Token (Global Management)",
+		Policies:    []*consulapi.ACLLink{{ID: policy.ID, Name: policy.Name}},
+		CreateTime:  time.Now().UTC(),
+	}
+	token.CreateIndex = c.nextIndex()
+	token.ModifyIndex = token.CreateIndex
+	c.aclTokens[token.AccessorID] = token
+	c.aclResetIndex = token.CreateIndex
+
+	return token, http.StatusOK
+}
+
+func (c *fakeConsul) handleACLAuthMethod(w http.ResponseWriter, r *http.Request, rest string) (interface{}, int) {
+	name := strings.TrimPrefix(rest, "/")
+
+	switch r.Method {
+	case "GET":
+		method, ok := c.aclAuthMethods[name]
+		if !ok {
+			return "", http.StatusNotFound
+		}
+		return method, http.StatusOK
+	case "PUT":
+		var method consulapi.ACLAuthMethod
+		if err := decode(r, &method); err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+		switch method.Type {
+		case "kubernetes", "jwt", "oidc":
+		default:
+			return "Invalid Auth Method: Type should be one of: [jwt kubernetes oidc]", http.StatusBadRequest
+		}
+		if name == "" {
+			if _, exists := c.aclAuthMethods[method.Name]; exists {
+				return "Invalid Auth Method: Name is already in use", http.StatusBadRequest
+			}
+			method.CreateIndex = c.nextIndex()
+		} else {
+			existing, ok := c.aclAuthMethods[name]
+			if !ok {
+				return "Cannot find auth method to update", http.StatusBadRequest
+			}
+			if existing.Type != method.Type {
+				return "Cannot change the type of an auth method", http.StatusBadRequest
+			}
+			method.CreateIndex = existing.CreateIndex
+		}
+		method.ModifyIndex = c.nextIndex()
+		c.aclAuthMethods[method.Name] = &method
+		return &method, http.StatusOK
+	case "DELETE":
+		// As in Consul, the binding rules of the auth method are deleted
+		// along with it.
+		delete(c.aclAuthMethods, name)
+		for id, rule := range c.aclBindingRules {
+			if rule.AuthMethod == name {
+				delete(c.aclBindingRules, id)
+			}
+		}
+		c.nextIndex()
+		return true, http.StatusOK
+	}
+	return methodNotAllowed(r)
+}
+
+func (c *fakeConsul) handleACLBindingRule(w http.ResponseWriter, r *http.Request, rest string) (interface{}, int) {
+	id := strings.TrimPrefix(rest, "/")
+
+	switch r.Method {
+	case "GET":
+		rule, ok := c.aclBindingRules[id]
+		if !ok {
+			return "", http.StatusNotFound
+		}
+		return rule, http.StatusOK
+	case "PUT":
+		var rule consulapi.ACLBindingRule
+		if err := decode(r, &rule); err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+		if _, ok := c.aclAuthMethods[rule.AuthMethod]; !ok {
+			return fmt.Sprintf("Invalid Binding Rule: unknown AuthMethod %q", rule.AuthMethod), http.StatusBadRequest
+		}
+		if _, err := matchFakeACLSelector(rule.Selector, nil); err != nil {
+			return fmt.Sprintf("invalid Binding Rule: Selector is invalid: %v", err), http.StatusBadRequest
+		}
+		if id == "" {
+			rule.ID = c.nextID()
+			rule.CreateIndex = c.nextIndex()
+		} else {
+			existing, ok := c.aclBindingRules[id]
+			if !ok {
+				return "Cannot find binding rule to update", http.StatusBadRequest
+			}
+			rule.ID = id
+			rule.CreateIndex = existing.CreateIndex
+		}
+		rule.ModifyIndex = c.nextIndex()
+		c.aclBindingRules[rule.ID] = &rule
+		return &rule, http.StatusOK
+	case "DELETE":
+		delete(c.aclBindingRules, id)
+		c.nextIndex()
+		return true, http.StatusOK
+	}
+	return methodNotAllowed(r)
+}
+
+// handleACLLogin creates a token for the identity of the bearer token,
+// bound to the services and roles of the binding rules it matches.
+func (c *fakeConsul) handleACLLogin(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "POST" {
+		return methodNotAllowed(r)
+	}
+
+	var params consulapi.ACLLoginParams
+	if err := decode(r, &params); err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	method, ok := c.aclAuthMethods[params.AuthMethod]
+	if !ok {
+		return fmt.Sprintf("auth method %q not found", params.AuthMethod), http.StatusForbidden
+	}
+	identity, ok := c.aclLoginIdentities[params.BearerToken]
+	if !ok {
+		return "Permission denied", http.StatusForbidden
+	}
+
+	token := &consulapi.ACLToken{
+		AccessorID:  c.nextID(),
+		SecretID:    c.nextID(),
+		Description: "token created via login",
+		AuthMethod:  method.Name,
+		Local:       method.TokenLocality != "global",
+		CreateTime:  time.Now().UTC(),
+	}
+	for _, id := range sortedACLBindingRuleIDs(c.aclBindingRules) {
+		rule := c.aclBindingRules[id]
+		if rule.AuthMethod != method.Name {
+			continue
+		}
+		if ok, _ := matchFakeACLSelector(rule.Selector, identity); !ok {
+			continue
+		}
+		name := rule.BindName
+		for k, v := range identity {
+			name = strings.Replace(name, "${"+k+"}", v, -1)
+		}
+		switch rule.BindType {
+		case consulapi.BindingRuleBindTypeService:
+			token.ServiceIdentities = append(token.ServiceIdentities, &consulapi.ACLServiceIdentity{ServiceName: name})
+		case consulapi.BindingRuleBindTypeRole:
+			for _, role := range c.aclRoles {
+				if role.Name == name {
+					token.Roles = append(token.Roles, &consulapi.ACLLink{ID: role.ID, Name: role.Name})
+				}
+			}
+		}
+	}
+	if len(token.ServiceIdentities) == 0 && len(token.Roles) == 0 {
+		return "Permission denied", http.StatusForbidden
+	}
+
+	token.CreateIndex = c.nextIndex()
+	token.ModifyIndex = token.CreateIndex
+	c.aclTokens[token.AccessorID] = token
+	return token, http.StatusOK
+}
+
+// matchFakeACLSelector evaluates the subset of the selectors of binding
+// rules made of clauses of the form `field == "value"` joined with "and".
+func matchFakeACLSelector(selector string, identity map[string]string) (bool, error) {
+	if strings.TrimSpace(selector) == "" {
+		return true, nil
+	}
+	match := true
+	for _, clause := range strings.Split(selector, " and ") {
+		parts := strings.SplitN(clause, "==", 2)
+		if len(parts) != 2 {
+			return false, fmt.Errorf("unsupported clause %q", clause)
+		}
+		field := strings.TrimSpace(parts[0])
+		value, err := strconv.Unquote(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return false, fmt.Errorf("unsupported value in clause %q", clause)
+		}
+		if identity[field] != value {
+			match = false
+		}
+	}
+	return match, nil
+}
+
+func (c *fakeConsul) handleACLReplication(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+	if c.aclReplication == nil {
+		return &consulapi.ACLReplicationStatus{ReplicationType: "legacy"}, http.StatusOK
+	}
+	return c.aclReplication, http.StatusOK
+}
+
+func sortedACLBindingRuleIDs(rules map[string]*consulapi.ACLBindingRule) []string {
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (c *fakeConsul) handleSessionCreate(w http.ResponseWriter, r *http.Request, _ string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	// The lock delay is sent as a duration string rather than as the
+	// nanoseconds of a SessionEntry.
+	var req struct {
+		Name      string
+		Node      string
+		Checks    []string
+		LockDelay string
+		Behavior  string
+		TTL       string
+	}
+	if err := decode(r, &req); err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+
+	session := &consulapi.SessionEntry{
+		Name:      req.Name,
+		Node:      req.Node,
+		Checks:    req.Checks,
+		LockDelay: 15 * time.Second,
+		Behavior:  req.Behavior,
+		TTL:       req.TTL,
+	}
+	if session.Node == "" {
+		session.Node = c.nodeName
+	}
+	if _, ok := c.nodes[session.Node]; !ok {
+		return fmt.Sprintf("Missing node registration %q", session.Node), http.StatusInternalServerError
+	}
+	if req.Checks == nil {
+		session.Checks = []string{"serfHealth"}
+	}
+	if req.LockDelay != "" {
+		d, err := time.ParseDuration(req.LockDelay)
+		if err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+		session.LockDelay = d
+	}
+	if session.Behavior == "" {
+		session.Behavior = consulapi.SessionBehaviorRelease
+	}
+
+	session.ID = c.nextID()
+	session.CreateIndex = c.index
+	c.sessions[session.ID] = session
+
+	return map[string]string{"ID": session.ID}, http.StatusOK
+}
+
+func (c *fakeConsul) handleSessionDestroy(w http.ResponseWriter, r *http.Request, id string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	session, ok := c.sessions[id]
+	if !ok {
+		return true, http.StatusOK
+	}
+	delete(c.sessions, id)
+
+	// Invalidating the session releases or deletes the keys it locked.
+	index := c.nextIndex()
+	for key, pair := range c.kv {
+		if pair.Session != id {
+			continue
+		}
+		if session.Behavior == consulapi.SessionBehaviorDelete {
+			delete(c.kv, key)
+			continue
+		}
+		pair.Session = ""
+		pair.ModifyIndex = index
+	}
+
+	return true, http.StatusOK
+}
+
+func (c *fakeConsul) handleSessionInfo(w http.ResponseWriter, r *http.Request, id string) (interface{}, int) {
+	if r.Method != "GET" {
+		return methodNotAllowed(r)
+	}
+
+	sessions := []*consulapi.SessionEntry{}
+	if session, ok := c.sessions[id]; ok {
+		sessions = append(sessions, session)
+	}
+
+	return sessions, http.StatusOK
+}
+
+func (c *fakeConsul) handleSessionRenew(w http.ResponseWriter, r *http.Request, id string) (interface{}, int) {
+	if r.Method != "PUT" {
+		return methodNotAllowed(r)
+	}
+
+	session, ok := c.sessions[id]
+	if !ok {
+		return fmt.Sprintf("Session id '%s' not found", id), http.StatusNotFound
+	}
+	c.renewals[id]++
+
+	return []*consulapi.SessionEntry{session}, http.StatusOK
+}
+
+func (c *fakeConsul) handleQuery(w http.ResponseWriter, r *http.Request, rest string) (interface{}, int) {
+	id := strings.TrimPrefix(rest, "/")
+
+	switch {
+	case id == "" && r.Method == "POST":
+		var query consulapi.PreparedQueryDefinition
+		if err := decode(r, &query); err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+		query.ID = c.nextID()
+		c.queries[query.ID] = &query
+		return map[string]string{"ID": query.ID}, http.StatusOK
+
+	case id == "" && r.Method == "GET":
+		queries := []*consulapi.PreparedQueryDefinition{}
+		for _, query := range c.queries {
+			queries = append(queries, query)
+		}
+		return queries, http.StatusOK
+
+	case id == "":
+		return methodNotAllowed(r)
+	}
+
+	if _, ok := c.queries[id]; !ok {
+		return "Query not found", http.StatusNotFound
+	}
+
+	switch r.Method {
+	case "GET":
+		return []*consulapi.PreparedQueryDefinition{c.queries[id]}, http.StatusOK
+
+	case "PUT":
+		var query consulapi.PreparedQueryDefinition
+		if err := decode(r, &query); err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+		query.ID = id
+		c.queries[id] = &query
+		c.nextIndex()
+		return nil, http.StatusOK
+
+	case "DELETE":
+		delete(c.queries, id)
+		c.nextIndex()
+		return nil, http.StatusOK
+	}
+
+	return methodNotAllowed(r)
+}
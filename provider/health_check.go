@@ -0,0 +1,534 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// healthCheckHeaderResource describes a header sent by HTTP checks.
+var healthCheckHeaderResource = &schema.Resource{
+	Schema: map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+
+		"value": {
+			Type:     schema.TypeList,
+			Required: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	},
+}
+
+// healthCheckTypes are the mutually exclusive attributes selecting the kind
+// of a health check.
+var healthCheckTypes = []string{"http", "tcp", "ttl", "args", "grpc", "docker_container_id"}
+
+// healthCheckSchema returns the attributes describing a health check.
+func healthCheckSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"check_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+
+		"name": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+
+		"notes": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"status": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"http": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"method": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"header": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem:     healthCheckHeaderResource,
+		},
+
+		"tls_skip_verify": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+
+		"tcp": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"ttl": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     makeValidationFunc("ttl", []interface{}{validateDurationMin("0ns")}),
+			DiffSuppressFunc: suppressEquivalentDurations,
+		},
+
+		"args": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+
+		"grpc": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"grpc_use_tls": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+
+		"docker_container_id": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"shell": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+
+		"interval": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     makeValidationFunc("interval", []interface{}{validateDurationMin("0ns")}),
+			DiffSuppressFunc: suppressEquivalentDurations,
+		},
+
+		"timeout": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     makeValidationFunc("timeout", []interface{}{validateDurationMin("0ns")}),
+			DiffSuppressFunc: suppressEquivalentDurations,
+		},
+
+		"deregister_critical_service_after": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateFunc:     makeValidationFunc("deregister_critical_service_after", []interface{}{validateDurationMin("0ns")}),
+			DiffSuppressFunc: suppressEquivalentDurations,
+		},
+	}
+}
+
+// catalogHealthCheckSchema returns the attributes describing a check
+// registered in the catalog. Catalog checks are not run by any agent but
+// updated by external monitoring, so they only describe the monitored
+// endpoint and are bound to the node, or to one of its services.
+func catalogHealthCheckSchema() map[string]*schema.Schema {
+	s := healthCheckSchema()
+	for _, k := range []string{"ttl", "args", "grpc", "grpc_use_tls", "docker_container_id", "shell"} {
+		delete(s, k)
+	}
+
+	// The ID of a catalog check defaults to its name rather than to its
+	// position, so it is kept as given.
+	s["check_id"].Computed = false
+
+	s["status"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		Computed: true,
+		ValidateFunc: makeValidationFunc("status", []interface{}{
+			validateRegexp(`^(passing|warning|critical|maintenance)$`),
+		}),
+	}
+
+	s["service_id"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+
+	return s
+}
+
+// suppressEquivalentDurations ignores differences between durations written
+// differently, such as "1m" and "1m0s", which Consul normalizes.
+func suppressEquivalentDurations(k, old, new string, d *schema.ResourceData) bool {
+	o, err := time.ParseDuration(old)
+	if err != nil {
+		return false
+	}
+	n, err := time.ParseDuration(new)
+	if err != nil {
+		return false
+	}
+	return o == n
+}
+
+// serviceCheckID returns the ID of the i-th of the count checks of a
+// service when none was given, which is the one Consul would assign.
+func serviceCheckID(serviceID string, i, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("service:%s", serviceID)
+	}
+	return fmt.Sprintf("service:%s:%d", serviceID, i+1)
+}
+
+// expandServiceChecks converts the check blocks of a service into the checks
+// to register along with it, filling in the ID of the checks without one.
+func expandServiceChecks(d *schema.ResourceData, serviceID string) (consulapi.AgentServiceChecks, error) {
+	rawChecks := d.Get("check").([]interface{})
+	checks := make(consulapi.AgentServiceChecks, 0, len(rawChecks))
+	for i, raw := range rawChecks {
+		m := raw.(map[string]interface{})
+
+		check, err := expandHealthCheck(m)
+		if err != nil {
+			return nil, err
+		}
+		if check.CheckID == "" {
+			check.CheckID = serviceCheckID(serviceID, i, len(rawChecks))
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// expandHealthCheck converts the attributes of a health check into the
+// definition registered with Consul.
+func expandHealthCheck(m map[string]interface{}) (*consulapi.AgentServiceCheck, error) {
+	name := m["name"].(string)
+
+	var kinds []string
+	for _, kind := range healthCheckTypes {
+		switch v := m[kind].(type) {
+		case string:
+			if v != "" {
+				kinds = append(kinds, kind)
+			}
+		case []interface{}:
+			if len(v) > 0 {
+				kinds = append(kinds, kind)
+			}
+		}
+	}
+	if len(kinds) != 1 {
+		return nil, fmt.Errorf("Health check '%s' must set exactly one of %v, got %v", name, healthCheckTypes, kinds)
+	}
+	if kinds[0] != "ttl" && m["interval"].(string) == "" {
+		return nil, fmt.Errorf("Health check '%s' requires an interval", name)
+	}
+
+	check := &consulapi.AgentServiceCheck{
+		CheckID:                        m["check_id"].(string),
+		Name:                           name,
+		Notes:                          m["notes"].(string),
+		Status:                         m["status"].(string),
+		HTTP:                           m["http"].(string),
+		Method:                         m["method"].(string),
+		TLSSkipVerify:                  m["tls_skip_verify"].(bool),
+		TCP:                            m["tcp"].(string),
+		TTL:                            m["ttl"].(string),
+		GRPC:                           m["grpc"].(string),
+		GRPCUseTLS:                     m["grpc_use_tls"].(bool),
+		DockerContainerID:              m["docker_container_id"].(string),
+		Shell:                          m["shell"].(string),
+		Interval:                       m["interval"].(string),
+		Timeout:                        m["timeout"].(string),
+		DeregisterCriticalServiceAfter: m["deregister_critical_service_after"].(string),
+	}
+
+	for _, raw := range m["args"].([]interface{}) {
+		check.Args = append(check.Args, raw.(string))
+	}
+
+	if headers := m["header"].(*schema.Set).List(); len(headers) > 0 {
+		check.Header = make(map[string][]string, len(headers))
+		for _, raw := range headers {
+			h := raw.(map[string]interface{})
+			name := h["name"].(string)
+			for _, value := range h["value"].([]interface{}) {
+				check.Header[name] = append(check.Header[name], value.(string))
+			}
+		}
+	}
+
+	return check, nil
+}
+
+// flattenHealthCheck merges the definition of a check read from the agent
+// into its attributes in old. The agent does not report the TTL, arguments,
+// gRPC or Docker settings of a check, so these are kept from old.
+func flattenHealthCheck(check *consulapi.AgentCheck, old map[string]interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(old))
+	for k, v := range old {
+		m[k] = v
+	}
+
+	m["check_id"] = check.CheckID
+	m["name"] = check.Name
+	m["notes"] = check.Notes
+	flattenHealthCheckDefinition(check.Definition, m)
+
+	return m
+}
+
+// flattenHealthCheckDefinition stores the attributes of the definition of a
+// check into m.
+func flattenHealthCheckDefinition(def consulapi.HealthCheckDefinition, m map[string]interface{}) {
+	headerNames := make([]string, 0, len(def.Header))
+	for name := range def.Header {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+
+	headers := make([]interface{}, 0, len(headerNames))
+	for _, name := range headerNames {
+		values := make([]interface{}, 0, len(def.Header[name]))
+		for _, value := range def.Header[name] {
+			values = append(values, value)
+		}
+		headers = append(headers, map[string]interface{}{
+			"name":  name,
+			"value": values,
+		})
+	}
+
+	m["http"] = def.HTTP
+	m["method"] = def.Method
+	m["header"] = schema.NewSet(schema.HashResource(healthCheckHeaderResource), headers)
+	m["tls_skip_verify"] = def.TLSSkipVerify
+	m["tcp"] = def.TCP
+	m["interval"] = formatCheckDuration(def.IntervalDuration)
+	m["timeout"] = formatCheckDuration(def.TimeoutDuration)
+	m["deregister_critical_service_after"] = formatCheckDuration(def.DeregisterCriticalServiceAfterDuration)
+}
+
+func formatCheckDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// deregisterRemovedServiceChecks deregisters the checks that were removed
+// from the check blocks of d since the last apply. Checks bound to the
+// service by other means, such as consulclient_agent_check, are left alone.
+func deregisterRemovedServiceChecks(agent *consulapi.Agent, d *schema.ResourceData, serviceID string, checks consulapi.AgentServiceChecks) error {
+	current := make(map[string]bool, len(checks))
+	for _, check := range checks {
+		current[check.CheckID] = true
+	}
+
+	o, _ := d.GetChange("check")
+	oldChecks := o.([]interface{})
+	for i, raw := range oldChecks {
+		id := raw.(map[string]interface{})["check_id"].(string)
+		if id == "" {
+			id = serviceCheckID(serviceID, i, len(oldChecks))
+		}
+		if current[id] {
+			continue
+		}
+
+		if err := agent.CheckDeregister(id); err != nil {
+			return fmt.Errorf("Failed to deregister check '%s' from Consul agent: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// readServiceChecks returns the checks of the service with the given ID
+// registered with the agent, in the order of the check blocks of d. Checks
+// that are gone are dropped so that they get registered again.
+func readServiceChecks(agent *consulapi.Agent, d *schema.ResourceData, serviceID string) ([]interface{}, error) {
+	agentChecks, err := agent.Checks()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get checks from Consul agent: %v", err)
+	}
+
+	rawChecks := d.Get("check").([]interface{})
+	checks := make([]interface{}, 0, len(rawChecks))
+	for i, raw := range rawChecks {
+		old := raw.(map[string]interface{})
+		id := old["check_id"].(string)
+		if id == "" {
+			id = serviceCheckID(serviceID, i, len(rawChecks))
+		}
+
+		check, ok := agentChecks[id]
+		if !ok || check.ServiceID != serviceID {
+			continue
+		}
+
+		checks = append(checks, flattenHealthCheck(check, old))
+	}
+
+	return checks, nil
+}
+
+// expandCatalogHealthChecks converts the check blocks of d into the checks
+// to register on node, filling in the ID of the checks without one.
+func expandCatalogHealthChecks(d *schema.ResourceData, node string) consulapi.HealthChecks {
+	rawChecks := d.Get("check").([]interface{})
+	checks := make(consulapi.HealthChecks, 0, len(rawChecks))
+	for _, raw := range rawChecks {
+		m := raw.(map[string]interface{})
+
+		check := &consulapi.HealthCheck{
+			Node:      node,
+			CheckID:   m["check_id"].(string),
+			Name:      m["name"].(string),
+			Notes:     m["notes"].(string),
+			Status:    m["status"].(string),
+			ServiceID: m["service_id"].(string),
+			Definition: consulapi.HealthCheckDefinition{
+				HTTP:          m["http"].(string),
+				Method:        m["method"].(string),
+				TLSSkipVerify: m["tls_skip_verify"].(bool),
+				TCP:           m["tcp"].(string),
+			},
+		}
+		if check.CheckID == "" {
+			check.CheckID = check.Name
+		}
+		if check.Status == "" {
+			check.Status = consulapi.HealthCritical
+		}
+
+		// Durations were validated at plan time.
+		def := &check.Definition
+		def.IntervalDuration, _ = time.ParseDuration(m["interval"].(string))
+		def.TimeoutDuration, _ = time.ParseDuration(m["timeout"].(string))
+		def.DeregisterCriticalServiceAfterDuration, _ = time.ParseDuration(m["deregister_critical_service_after"].(string))
+
+		for _, raw := range m["header"].(*schema.Set).List() {
+			h := raw.(map[string]interface{})
+			if def.Header == nil {
+				def.Header = make(map[string][]string)
+			}
+			name := h["name"].(string)
+			for _, value := range h["value"].([]interface{}) {
+				def.Header[name] = append(def.Header[name], value.(string))
+			}
+		}
+
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// deregisterRemovedCatalogHealthChecks deregisters from node the checks that
+// were removed from the check blocks of d since the last apply.
+func deregisterRemovedCatalogHealthChecks(catalog *consulapi.Catalog, d *schema.ResourceData, node string, checks consulapi.HealthChecks, wOpts *consulapi.WriteOptions) error {
+	current := make(map[string]bool, len(checks))
+	for _, check := range checks {
+		current[check.CheckID] = true
+	}
+
+	o, _ := d.GetChange("check")
+	for _, raw := range o.([]interface{}) {
+		old := raw.(map[string]interface{})
+		id := old["check_id"].(string)
+		if id == "" {
+			id = old["name"].(string)
+		}
+		if current[id] {
+			continue
+		}
+
+		deregistration := &consulapi.CatalogDeregistration{
+			Node:       node,
+			Datacenter: wOpts.Datacenter,
+			CheckID:    id,
+		}
+		if _, err := catalog.Deregister(deregistration, wOpts); err != nil {
+			return fmt.Errorf("Failed to deregister check '%s' from Consul catalog node '%s': %v", id, node, err)
+		}
+	}
+	return nil
+}
+
+// importCatalogHealthChecks sets the check blocks of d to all the checks
+// registered on node, so that they are then read by readCatalogHealthChecks.
+func importCatalogHealthChecks(health *consulapi.Health, d *schema.ResourceData, node string, qOpts *consulapi.QueryOptions) error {
+	nodeChecks, _, err := health.Node(node, qOpts)
+	if err != nil {
+		return fmt.Errorf("Failed to get checks of Consul catalog node '%s': %v", node, err)
+	}
+
+	checks := make([]interface{}, 0, len(nodeChecks))
+	for _, check := range nodeChecks {
+		// The ID defaults to the name, so it is left out when equal.
+		id := check.CheckID
+		if id == check.Name {
+			id = ""
+		}
+		checks = append(checks, map[string]interface{}{
+			"check_id": id,
+			"name":     check.Name,
+		})
+	}
+	return d.Set("check", checks)
+}
+
+// readCatalogHealthChecks returns the checks registered on node, in the
+// order of the check blocks of d. Checks that are gone are dropped so that
+// they get registered again, and checks registered by others are ignored.
+func readCatalogHealthChecks(health *consulapi.Health, d *schema.ResourceData, node string, qOpts *consulapi.QueryOptions) ([]interface{}, error) {
+	nodeChecks, _, err := health.Node(node, qOpts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get checks of Consul catalog node '%s': %v", node, err)
+	}
+
+	byID := make(map[string]*consulapi.HealthCheck, len(nodeChecks))
+	for _, check := range nodeChecks {
+		byID[check.CheckID] = check
+	}
+
+	rawChecks := d.Get("check").([]interface{})
+	checks := make([]interface{}, 0, len(rawChecks))
+	for _, raw := range rawChecks {
+		old := raw.(map[string]interface{})
+		id := old["check_id"].(string)
+		if id == "" {
+			id = old["name"].(string)
+		}
+
+		check, ok := byID[id]
+		if !ok {
+			continue
+		}
+
+		// Keep the ID as it was given, since it defaults to the name of the
+		// check when left empty.
+		m := make(map[string]interface{}, len(old))
+		for k, v := range old {
+			m[k] = v
+		}
+		m["name"] = check.Name
+		m["notes"] = check.Notes
+		m["status"] = check.Status
+		m["service_id"] = check.ServiceID
+		flattenHealthCheckDefinition(check.Definition, m)
+
+		checks = append(checks, m)
+	}
+
+	return checks, nil
+}
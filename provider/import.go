@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Resources can be imported either by their plain ID, or by an ID of the form
+//
+//	<scheme>://<host>/<datacenter>/<id>
+//
+// which also sets the connection target of the imported resource, so that
+// objects can be imported from endpoints other than the provider's one.
+// The datacenter may be left empty to use the one of the agent, e.g.
+// "https://consul.example.com:8501//web".
+var importTargetSchemes = []string{"http", "https"}
+
+// resourceConsulImportState is an ImportStateFunc that parses the connection
+// target out of the import ID and otherwise passes the ID through to Read.
+func resourceConsulImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := parseImportTarget(d); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// setImportDefaults sets the attributes of s that have a default value and
+// were not set from the import ID. They are only given in the configuration
+// and are not read back from Consul, so an imported resource would otherwise
+// differ from a configuration leaving them unset.
+func setImportDefaults(d *schema.ResourceData, s map[string]*schema.Schema) error {
+	for k, v := range s {
+		if v.Default == nil {
+			continue
+		}
+		if _, ok := d.GetOk(k); ok {
+			continue
+		}
+		if err := d.Set(k, v.Default); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseImportTarget sets the scheme, host and datacenter of the resource
+// being imported if they are part of its ID, and replaces the ID with the
+// plain resource ID.
+func parseImportTarget(d *schema.ResourceData) error {
+	id := d.Id()
+
+	var scheme string
+	for _, s := range importTargetSchemes {
+		if strings.HasPrefix(id, s+"://") {
+			scheme = s
+			break
+		}
+	}
+	if scheme == "" {
+		return nil
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(id, scheme+"://"), "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return fmt.Errorf("Invalid import ID %q: expected <scheme>://<host>/<datacenter>/<id>", id)
+	}
+	host, dc, resourceID := parts[0], parts[1], parts[2]
+
+	if err := d.Set("scheme", scheme); err != nil {
+		return err
+	}
+	if err := d.Set("host", host); err != nil {
+		return err
+	}
+	if dc != "" {
+		if err := d.Set("datacenter", dc); err != nil {
+			return fmt.Errorf("Invalid import ID %q: the datacenter cannot be set on this resource", id)
+		}
+	}
+
+	d.SetId(resourceID)
+	return nil
+}
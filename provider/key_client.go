@@ -6,6 +6,7 @@ package provider
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	consulapi "github.com/hashicorp/consul/api"
 )
@@ -16,6 +17,15 @@ type keyClient struct {
 	client *consulapi.KV
 	qOpts  *consulapi.QueryOptions
 	wOpts  *consulapi.WriteOptions
+
+	// indexes records the ModifyIndex of the keys read so far. When cas is
+	// set, Put and Delete only modify keys that still have the recorded
+	// index, and Put only creates keys for which none is recorded.
+	indexes map[string]uint64
+	cas     bool
+
+	// flags records the flags of the keys read so far.
+	flags map[string]uint64
 }
 
 func newKeyClient(realClient *consulapi.KV, dc, token string) *keyClient {
@@ -23,9 +33,11 @@ func newKeyClient(realClient *consulapi.KV, dc, token string) *keyClient {
 	wOpts := &consulapi.WriteOptions{Datacenter: dc, Token: token}
 
 	return &keyClient{
-		client: realClient,
-		qOpts:  qOpts,
-		wOpts:  wOpts,
+		client:  realClient,
+		qOpts:   qOpts,
+		wOpts:   wOpts,
+		indexes: make(map[string]uint64),
+		flags:   make(map[string]uint64),
 	}
 }
 
@@ -41,6 +53,11 @@ func (c *keyClient) Get(path string) (string, error) {
 	value := ""
 	if pair != nil {
 		value = string(pair.Value)
+		c.indexes[path] = pair.ModifyIndex
+		c.flags[path] = pair.Flags
+	} else {
+		delete(c.indexes, path)
+		delete(c.flags, path)
 	}
 	return value, nil
 }
@@ -56,20 +73,58 @@ func (c *keyClient) GetUnderPrefix(pathPrefix string) (map[string]string, error)
 			"Failed to list Consul keys under prefix '%s': %s", pathPrefix, err,
 		)
 	}
+	for path := range c.indexes {
+		if strings.HasPrefix(path, pathPrefix) {
+			delete(c.indexes, path)
+			delete(c.flags, path)
+		}
+	}
 	value := map[string]string{}
 	for _, pair := range pairs {
 		subKey := pair.Key[len(pathPrefix):]
 		value[subKey] = string(pair.Value)
+		c.indexes[pair.Key] = pair.ModifyIndex
+		c.flags[pair.Key] = pair.Flags
 	}
 	return value, nil
 }
 
-func (c *keyClient) Put(path, value string) error {
+// GetKeysUnderPrefix lists the keys under pathPrefix. If separator is not
+// empty, only the keys up to the first separator after the prefix are
+// listed, and the ones that have subkeys are listed once, with a trailing
+// separator.
+func (c *keyClient) GetKeysUnderPrefix(pathPrefix, separator string) ([]string, error) {
 	log.Printf(
-		"[DEBUG] Setting key '%s' to '%v' in %s",
-		path, value, c.wOpts.Datacenter,
+		"[DEBUG] Listing key names under '%s' with separator '%s' in %s",
+		pathPrefix, separator, c.qOpts.Datacenter,
 	)
-	pair := consulapi.KVPair{Key: path, Value: []byte(value)}
+	keys, _, err := c.client.Keys(pathPrefix, separator, c.qOpts)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Failed to list Consul keys under prefix '%s': %s", pathPrefix, err,
+		)
+	}
+	return keys, nil
+}
+
+func (c *keyClient) Put(path, value string, flags uint64) error {
+	// Values are not logged, as they may be sensitive.
+	log.Printf(
+		"[DEBUG] Setting key '%s' to a %d bytes value with flags %d in %s",
+		path, len(value), flags, c.wOpts.Datacenter,
+	)
+	pair := consulapi.KVPair{Key: path, Value: []byte(value), Flags: flags}
+	if c.cas {
+		pair.ModifyIndex = c.indexes[path]
+		ok, _, err := c.client.CAS(&pair, c.wOpts)
+		if err != nil {
+			return fmt.Errorf("Failed to write Consul key '%s': %s", path, err)
+		}
+		if !ok {
+			return c.conflictError(path)
+		}
+		return nil
+	}
 	if _, err := c.client.Put(&pair, c.wOpts); err != nil {
 		return fmt.Errorf("Failed to write Consul key '%s': %s", path, err)
 	}
@@ -81,6 +136,9 @@ func (c *keyClient) Delete(path string) error {
 		"[DEBUG] Deleting key '%s' in %s",
 		path, c.wOpts.Datacenter,
 	)
+	if index, ok := c.indexes[path]; c.cas && ok {
+		return c.deleteCAS(path, index)
+	}
 	if _, err := c.client.Delete(path, c.wOpts); err != nil {
 		return fmt.Errorf("Failed to delete Consul key '%s': %s", path, err)
 	}
@@ -92,8 +150,170 @@ func (c *keyClient) DeleteUnderPrefix(pathPrefix string) error {
 		"[DEBUG] Deleting all keys under prefix '%s' in %s",
 		pathPrefix, c.wOpts.Datacenter,
 	)
-	if _, err := c.client.DeleteTree(pathPrefix, c.wOpts); err != nil {
-		return fmt.Errorf("Failed to delete Consul keys under '%s': %s", pathPrefix, err)
+	txn := c.NewTxn()
+	for path := range c.indexes {
+		if strings.HasPrefix(path, pathPrefix) {
+			txn.Check(path)
+		}
+	}
+	txn.DeleteTree(pathPrefix)
+	return txn.Commit()
+}
+
+func (c *keyClient) deleteCAS(path string, index uint64) error {
+	pair := consulapi.KVPair{Key: path, ModifyIndex: index}
+	ok, _, err := c.client.DeleteCAS(&pair, c.wOpts)
+	if err != nil {
+		return fmt.Errorf("Failed to delete Consul key '%s': %s", path, err)
+	}
+	if !ok {
+		return c.conflictError(path)
+	}
+	return nil
+}
+
+// conflictError returns the error reported when a CAS operation on path
+// fails because the key changed since it was last read.
+func (c *keyClient) conflictError(path string) error {
+	if index, ok := c.indexes[path]; ok {
+		return fmt.Errorf(
+			"Conflict writing Consul key '%s': it was modified since it was last read at index %d; refresh and apply again",
+			path, index,
+		)
+	}
+	return fmt.Errorf(
+		"Conflict writing Consul key '%s': it was created since it was last read; import it or remove it and apply again",
+		path,
+	)
+}
+
+// maxTxnOps is the maximum number of operations Consul accepts in a single
+// transaction.
+const maxTxnOps = 64
+
+// keyTxn is a set of writes to the key/value store applied together through
+// the transaction endpoint, so that either all or none of them are applied.
+//
+// Consul limits the number of operations of a transaction, so larger sets
+// are split into transactions of up to maxTxnOps operations committed in
+// order. Each of them is still applied atomically, but a failure leaves the
+// ones committed before it in place, as separate writes would.
+type keyTxn struct {
+	client *keyClient
+	ops    consulapi.KVTxnOps
+}
+
+// NewTxn returns an empty transaction. Like Put and Delete, its writes
+// check the recorded indexes of the keys when the client is in CAS mode.
+func (c *keyClient) NewTxn() *keyTxn {
+	return &keyTxn{client: c}
+}
+
+// Set sets the key path to value, with the given flags.
+func (t *keyTxn) Set(path, value string, flags uint64) {
+	op := &consulapi.KVTxnOp{Verb: consulapi.KVSet, Key: path, Value: []byte(value), Flags: flags}
+	if t.client.cas {
+		op.Verb = consulapi.KVCAS
+		op.Index = t.client.indexes[path]
+	}
+	t.ops = append(t.ops, op)
+}
+
+// Delete deletes the key path.
+func (t *keyTxn) Delete(path string) {
+	op := &consulapi.KVTxnOp{Verb: consulapi.KVDelete, Key: path}
+	if index, ok := t.client.indexes[path]; t.client.cas && ok {
+		op.Verb = consulapi.KVDeleteCAS
+		op.Index = index
+	}
+	t.ops = append(t.ops, op)
+}
+
+// DeleteTree deletes all the keys under pathPrefix.
+func (t *keyTxn) DeleteTree(pathPrefix string) {
+	t.ops = append(t.ops, &consulapi.KVTxnOp{Verb: consulapi.KVDeleteTree, Key: pathPrefix})
+}
+
+// Check makes the transaction fail if the key path was modified since it
+// was last read. It does nothing unless the client is in CAS mode.
+func (t *keyTxn) Check(path string) {
+	index, ok := t.client.indexes[path]
+	if !t.client.cas || !ok {
+		return
+	}
+	t.ops = append(t.ops, &consulapi.KVTxnOp{Verb: consulapi.KVCheckIndex, Key: path, Index: index})
+}
+
+// Commit applies the writes of the transaction.
+func (t *keyTxn) Commit() error {
+	c := t.client
+	if len(t.ops) > maxTxnOps {
+		log.Printf(
+			"[WARN] Writing %d Consul keys in %s in %d transactions of up to %d operations; the writes are not atomic as a whole",
+			len(t.ops), c.wOpts.Datacenter, (len(t.ops)+maxTxnOps-1)/maxTxnOps, maxTxnOps,
+		)
+	}
+
+	for start := 0; start < len(t.ops); start += maxTxnOps {
+		end := start + maxTxnOps
+		if end > len(t.ops) {
+			end = len(t.ops)
+		}
+		if err := c.commit(t.ops[start:end]); err != nil {
+			return err
+		}
+	}
+	t.ops = nil
+	return nil
+}
+
+// commit applies ops in a single transaction.
+func (c *keyClient) commit(ops consulapi.KVTxnOps) error {
+	for _, op := range ops {
+		log.Printf(
+			"[DEBUG] Transaction in %s: %s key '%s'",
+			c.wOpts.Datacenter, op.Verb, op.Key,
+		)
+	}
+
+	qOpts := &consulapi.QueryOptions{Datacenter: c.wOpts.Datacenter, Token: c.wOpts.Token}
+	ok, resp, _, err := c.client.Txn(ops, qOpts)
+	if err != nil {
+		return fmt.Errorf("Failed to write Consul keys: %s", err)
+	}
+	if !ok {
+		for _, txnErr := range resp.Errors {
+			if txnErr.OpIndex < 0 || txnErr.OpIndex >= len(ops) {
+				continue
+			}
+			op := ops[txnErr.OpIndex]
+			switch op.Verb {
+			case consulapi.KVCAS, consulapi.KVDeleteCAS, consulapi.KVCheckIndex:
+				return c.conflictError(op.Key)
+			}
+			return fmt.Errorf("Failed to write Consul key '%s': %s", op.Key, txnErr.What)
+		}
+		return fmt.Errorf("Failed to write Consul keys: transaction rolled back")
+	}
+
+	// Record the new indexes of the keys written, so that subsequent
+	// writes in CAS mode can follow.
+	for _, op := range ops {
+		switch op.Verb {
+		case consulapi.KVDelete, consulapi.KVDeleteCAS:
+			delete(c.indexes, op.Key)
+		case consulapi.KVDeleteTree:
+			for path := range c.indexes {
+				if strings.HasPrefix(path, op.Key) {
+					delete(c.indexes, path)
+				}
+			}
+		}
+	}
+	for _, pair := range resp.Results {
+		if pair != nil {
+			c.indexes[pair.Key] = pair.ModifyIndex
+		}
 	}
 	return nil
 }
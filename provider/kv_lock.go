@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// kvLockSchema returns the schema of the lock block of the resources
+// writing to the key/value store, which makes them hold a Consul lock while
+// writing so that concurrent applies managing the same keys are serialized.
+func kvLockSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+
+				// A session is created for the duration of the lock
+				// unless one is given, e.g. by a consulclient_session.
+				"session": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+
+				"wait_time": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "15s",
+					DiffSuppressFunc: suppressEquivalentDurations,
+					ValidateFunc: makeValidationFunc("wait_time", []interface{}{
+						validateDurationMin("0ns"),
+					}),
+				},
+			},
+		},
+	}
+}
+
+// kvCASSchema returns the schema of the cas attribute of the resources
+// writing to the key/value store. In CAS mode keys are only written or
+// deleted if they were not modified since they were last read.
+func kvCASSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+	}
+}
+
+// kvModifyIndexesSchema returns the schema of the modify_indexes attribute,
+// which records the ModifyIndex of the managed keys as of the last read.
+func kvModifyIndexesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Computed: true,
+	}
+}
+
+// acquireKVLock acquires the lock described by the lock block of d, if any,
+// and returns a function releasing it.
+func acquireKVLock(client *consulapi.Client, d *schema.ResourceData) (func(), error) {
+	if _, ok := d.GetOk("lock.0"); !ok {
+		return func() {}, nil
+	}
+
+	key := d.Get("lock.0.key").(string)
+	waitTime, err := time.ParseDuration(d.Get("lock.0.wait_time").(string))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse wait_time of lock '%s': %v", key, err)
+	}
+
+	lock, err := client.LockOpts(&consulapi.LockOptions{
+		Key:          key,
+		Session:      d.Get("lock.0.session").(string),
+		SessionName:  "terraform",
+		LockWaitTime: waitTime,
+		LockTryOnce:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to set up lock '%s': %v", key, err)
+	}
+
+	log.Printf("[DEBUG] Acquiring lock '%s'", key)
+	lockCh, err := lock.Lock(nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to acquire lock '%s': %v", key, err)
+	}
+	if lockCh == nil {
+		return nil, fmt.Errorf("Failed to acquire lock '%s': still held by another session after %s", key, waitTime)
+	}
+
+	return func() {
+		log.Printf("[DEBUG] Releasing lock '%s'", key)
+		if err := lock.Unlock(); err != nil {
+			log.Printf("[WARN] Failed to release lock '%s': %v", key, err)
+		}
+	}, nil
+}
+
+// expandModifyIndexes returns the modify_indexes recorded in the state of d.
+func expandModifyIndexes(d *schema.ResourceData) map[string]uint64 {
+	indexes := make(map[string]uint64)
+	for k, v := range d.Get("modify_indexes").(map[string]interface{}) {
+		index, err := strconv.ParseUint(v.(string), 10, 64)
+		if err != nil {
+			continue
+		}
+		indexes[k] = index
+	}
+	return indexes
+}
+
+// flattenModifyIndexes returns indexes as stored in modify_indexes.
+func flattenModifyIndexes(indexes map[string]uint64) map[string]interface{} {
+	m := make(map[string]interface{}, len(indexes))
+	for k, index := range indexes {
+		m[k] = strconv.FormatUint(index, 10)
+	}
+	return m
+}
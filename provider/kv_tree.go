@@ -0,0 +1,408 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/terraform/helper/schema"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Documents stored under a prefix of the key/value store are flattened into
+// one key per value, whose path is the path of the value in the document
+// joined with a separator. Arrays are either flattened the same way, with
+// the indexes of their elements as keys, or stored JSON-encoded as the value
+// of a single key.
+const (
+	keyTreeFormatJSON = "json"
+	keyTreeFormatYAML = "yaml"
+	keyTreeFormatHCL  = "hcl"
+
+	keyTreeArraysIndex = "index"
+	keyTreeArraysJSON  = "json"
+)
+
+// keyTreeOptions describes how a document is stored in the key/value store.
+type keyTreeOptions struct {
+	Format    string
+	Separator string
+	Arrays    string
+}
+
+// decodeKeyTreeDocument parses the document doc, in the format of opts, into
+// nested map[string]interface{} and []interface{} values.
+func decodeKeyTreeDocument(doc string, opts keyTreeOptions) (map[string]interface{}, error) {
+	var v interface{}
+	switch opts.Format {
+	case keyTreeFormatJSON:
+		dec := json.NewDecoder(strings.NewReader(doc))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("Failed to parse JSON document: %v", err)
+		}
+	case keyTreeFormatYAML:
+		if err := yaml.Unmarshal([]byte(doc), &v); err != nil {
+			return nil, fmt.Errorf("Failed to parse YAML document: %v", err)
+		}
+	case keyTreeFormatHCL:
+		if err := hcl.Unmarshal([]byte(doc), &v); err != nil {
+			return nil, fmt.Errorf("Failed to parse HCL document: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported document format '%s'", opts.Format)
+	}
+
+	m, ok := normalizeKeyTreeValue(v).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("The document must be an object, got %T", v)
+	}
+	return m, nil
+}
+
+// normalizeKeyTreeValue converts the objects decoded from YAML and HCL to
+// map[string]interface{}, as decoded from JSON.
+func normalizeKeyTreeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = normalizeKeyTreeValue(e)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalizeKeyTreeValue(e)
+		}
+		return m
+	case []map[string]interface{}:
+		// HCL decodes objects as lists of the blocks defining them.
+		if len(v) == 1 {
+			return normalizeKeyTreeValue(v[0])
+		}
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			l[i] = normalizeKeyTreeValue(e)
+		}
+		return l
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			l[i] = normalizeKeyTreeValue(e)
+		}
+		return l
+	}
+	return v
+}
+
+// flattenKeyTree returns the values of the document doc by subkey.
+func flattenKeyTree(doc map[string]interface{}, opts keyTreeOptions) (map[string]string, error) {
+	if opts.Separator == "" {
+		return nil, fmt.Errorf("The separator cannot be empty")
+	}
+	values := make(map[string]string)
+	if err := flattenKeyTreeValue(values, "", doc, opts); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func flattenKeyTreeValue(values map[string]string, path string, v interface{}, opts keyTreeOptions) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if k == "" || strings.Contains(k, opts.Separator) {
+				return fmt.Errorf("Key '%s' of the document at '%s' cannot be stored: keys must be non-empty and cannot contain the separator '%s'", k, path, opts.Separator)
+			}
+			if err := flattenKeyTreeValue(values, joinKeyTreePath(path, k, opts), e, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		if opts.Arrays == keyTreeArraysJSON {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("Failed to encode array at '%s': %v", path, err)
+			}
+			values[path] = string(b)
+			return nil
+		}
+		for i, e := range v {
+			if err := flattenKeyTreeValue(values, joinKeyTreePath(path, strconv.Itoa(i), opts), e, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	case nil:
+		values[path] = ""
+	case string:
+		values[path] = v
+	case bool:
+		values[path] = strconv.FormatBool(v)
+	case float64:
+		values[path] = strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		values[path] = fmt.Sprint(v)
+	}
+	return nil
+}
+
+func joinKeyTreePath(path, k string, opts keyTreeOptions) string {
+	if path == "" {
+		return k
+	}
+	return path + opts.Separator + k
+}
+
+// expandKeyTree returns the document stored in the given values by subkey.
+// Values are all strings, as they are stored, and with the index array
+// handling objects whose keys are the indexes 0 to n-1 are arrays.
+func expandKeyTree(values map[string]string, opts keyTreeOptions) map[string]interface{} {
+	doc := make(map[string]interface{})
+	paths := make([]string, 0, len(values))
+	for path := range values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		parts := strings.Split(path, opts.Separator)
+		m := doc
+		for i, part := range parts {
+			if i == len(parts)-1 {
+				if _, ok := m[part].(map[string]interface{}); ok {
+					log.Printf("[WARN] Key '%s' is both a value and a prefix of other keys; ignoring its value", path)
+					break
+				}
+				m[part] = expandKeyTreeLeaf(values[path], opts)
+				break
+			}
+			next, ok := m[part].(map[string]interface{})
+			if !ok {
+				if _, exists := m[part]; exists {
+					log.Printf("[WARN] Key '%s' is both a value and a prefix of other keys; ignoring its value", strings.Join(parts[:i+1], opts.Separator))
+				}
+				next = make(map[string]interface{})
+				m[part] = next
+			}
+			m = next
+		}
+	}
+
+	if opts.Arrays == keyTreeArraysIndex {
+		return expandKeyTreeArrays(doc).(map[string]interface{})
+	}
+	return doc
+}
+
+func expandKeyTreeLeaf(value string, opts keyTreeOptions) interface{} {
+	if opts.Arrays == keyTreeArraysJSON && strings.HasPrefix(value, "[") {
+		var l []interface{}
+		dec := json.NewDecoder(strings.NewReader(value))
+		dec.UseNumber()
+		if err := dec.Decode(&l); err == nil {
+			return expandKeyTreeNumbers(l)
+		}
+	}
+	return value
+}
+
+// expandKeyTreeNumbers converts the json.Number values of v into int64 or
+// float64 values, so that they are not encoded as strings in YAML.
+func expandKeyTreeNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = expandKeyTreeNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = expandKeyTreeNumbers(e)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+// expandKeyTreeArrays converts the objects of v whose keys are the indexes
+// 0 to n-1 into arrays.
+func expandKeyTreeArrays(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	for k, e := range m {
+		m[k] = expandKeyTreeArrays(e)
+	}
+	if !isKeyTreeArray(m) {
+		return m
+	}
+	l := make([]interface{}, len(m))
+	for k, e := range m {
+		i, _ := strconv.Atoi(k)
+		l[i] = e
+	}
+	return l
+}
+
+// isKeyTreeArray returns whether the keys of the non-empty object m are the
+// indexes 0 to n-1.
+func isKeyTreeArray(m map[string]interface{}) bool {
+	if len(m) == 0 {
+		return false
+	}
+	for k := range m {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(m) || strconv.Itoa(i) != k {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeKeyTreeDocument returns doc in the format of opts. HCL documents are
+// encoded as JSON, which HCL accepts.
+func encodeKeyTreeDocument(doc map[string]interface{}, opts keyTreeOptions) (string, error) {
+	if opts.Format == keyTreeFormatYAML {
+		b, err := yaml.Marshal(doc)
+		if err != nil {
+			return "", fmt.Errorf("Failed to encode YAML document: %v", err)
+		}
+		return string(b), nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return "", fmt.Errorf("Failed to encode JSON document: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// keyTreeValues returns the values by subkey of the document doc.
+func keyTreeValues(doc string, opts keyTreeOptions) (map[string]string, error) {
+	m, err := decodeKeyTreeDocument(doc, opts)
+	if err != nil {
+		return nil, err
+	}
+	return flattenKeyTree(m, opts)
+}
+
+// keyTreeWriteValues returns the values by subkey of the document doc, to be
+// written, checking that the document would be read back as written.
+func keyTreeWriteValues(doc string, opts keyTreeOptions) (map[string]string, error) {
+	m, err := decodeKeyTreeDocument(doc, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateKeyTreeDocument(m, opts); err != nil {
+		return nil, err
+	}
+	return flattenKeyTree(m, opts)
+}
+
+// validateKeyTreeDocument rejects the documents that would not be read back
+// as written, so that the plan would never settle once the document is
+// rebuilt from the keys: empty objects, and empty arrays unless they are
+// JSON-encoded, are stored as no key at all. With the index array handling,
+// objects whose keys are the indexes 0 to n-1 are read back as arrays, and
+// with the json one, so are the strings holding a JSON array.
+func validateKeyTreeDocument(doc map[string]interface{}, opts keyTreeOptions) error {
+	// An empty document is read back as an empty document.
+	if len(doc) == 0 {
+		return nil
+	}
+	return validateKeyTreeValue("", doc, opts)
+}
+
+func validateKeyTreeValue(path string, v interface{}, opts keyTreeOptions) error {
+	at := "at '" + path + "'"
+	if path == "" {
+		at = "at the top of the document"
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return fmt.Errorf("The object %s is empty; empty objects cannot be stored", at)
+		}
+		if opts.Arrays == keyTreeArraysIndex && isKeyTreeArray(v) {
+			return fmt.Errorf("The keys of the object %s are the indexes 0 to %d; it would be read back as an array", at, len(v)-1)
+		}
+		for k, e := range v {
+			if err := validateKeyTreeValue(joinKeyTreePath(path, k, opts), e, opts); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		// JSON-encoded arrays are read back as they are.
+		if opts.Arrays == keyTreeArraysJSON {
+			return nil
+		}
+		if len(v) == 0 {
+			return fmt.Errorf("The array %s is empty; empty arrays can only be stored with the json array handling", at)
+		}
+		for i, e := range v {
+			if err := validateKeyTreeValue(joinKeyTreePath(path, strconv.Itoa(i), opts), e, opts); err != nil {
+				return err
+			}
+		}
+	case string:
+		if _, ok := expandKeyTreeLeaf(v, opts).([]interface{}); ok {
+			return fmt.Errorf("The string %s holds a JSON array; it would be read back as an array", at)
+		}
+	}
+	return nil
+}
+
+// keyTreeOptionsFromResourceData returns the keyTreeOptions given by the
+// format, separator and array_handling attributes of d.
+func keyTreeOptionsFromResourceData(d *schema.ResourceData) keyTreeOptions {
+	return keyTreeOptions{
+		Format:    d.Get("format").(string),
+		Separator: d.Get("separator").(string),
+		Arrays:    d.Get("array_handling").(string),
+	}
+}
+
+// suppressEquivalentKeyTreeDocuments suppresses the differences between
+// documents that are stored as the same keys, such as the same document with
+// a different indentation or order of keys, or with numbers given as strings.
+func suppressEquivalentKeyTreeDocuments(k, old, new string, d *schema.ResourceData) bool {
+	opts := keyTreeOptionsFromResourceData(d)
+	oldValues, err := keyTreeValues(old, opts)
+	if err != nil {
+		return false
+	}
+	newValues, err := keyTreeValues(new, opts)
+	if err != nil {
+		return false
+	}
+	return equalKeyTreeValues(oldValues, newValues)
+}
+
+func equalKeyTreeValues(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Values of the key/value store are arbitrary bytes, while Terraform
+// attributes are UTF-8 strings. Values that are not text, such as TLS
+// bundles or compressed payloads, are therefore exchanged base64-encoded
+// through the *_base64 attributes, and compared by the hash of the bytes
+// they encode rather than by their encoding.
+
+// decodeBase64Value returns the bytes encoded in the base64 string v as a
+// string. Whitespace is ignored, so that line-wrapped encodings are
+// accepted.
+func decodeBase64Value(v string) (string, error) {
+	v = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, v)
+	b, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 value: %v", err)
+	}
+	return string(b), nil
+}
+
+// encodeBase64Value returns the base64 encoding of the bytes of value.
+func encodeBase64Value(value string) string {
+	return base64.StdEncoding.EncodeToString([]byte(value))
+}
+
+// base64ContentHash returns the SHA-256 hash of the bytes encoded in v, or
+// of v itself if it is not valid base64.
+func base64ContentHash(v string) string {
+	if value, err := decodeBase64Value(v); err == nil {
+		v = value
+	}
+	sum := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(sum[:])
+}
+
+// Values marked sensitive are kept out of the state and of the outputs of
+// the resources: only a hash of them is stored, prefixed with
+// sensitiveValuePrefix, against which the configured values are compared.
+// Whether a stored value is such a hash is told by the sensitive flags of
+// the resources rather than by the value, so that values that happen to
+// start with the prefix are handled like any other.
+const sensitiveValuePrefix = "sha256:"
+
+// sensitiveValueHash returns the hash stored for the sensitive value.
+func sensitiveValueHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return sensitiveValuePrefix + hex.EncodeToString(sum[:])
+}
+
+// sensitiveBase64Hash returns the hash stored for the sensitive value whose
+// base64 encoding is v.
+func sensitiveBase64Hash(v string) string {
+	if value, err := decodeBase64Value(v); err == nil {
+		return sensitiveValueHash(value)
+	}
+	return sensitiveValueHash(v)
+}
@@ -4,6 +4,10 @@
 package provider
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/mitchellh/mapstructure"
@@ -68,25 +72,64 @@ func Provider() terraform.ResourceProvider {
 					"CONSUL_HTTP_TOKEN",
 				}, ""),
 			},
+
+			// token and token_file cannot declare the attributes they
+			// conflict with, as their defaults count as set, so only one
+			// of the token sources being set is checked when configuring.
+			"token_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("CONSUL_HTTP_TOKEN_FILE", ""),
+			},
+
+			"token_command": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"token", "token_file"},
+			},
+
+			"token_command_ttl": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  defaultTokenCommandTTL,
+				ValidateFunc: makeValidationFunc("token_command_ttl", []interface{}{
+					validateDurationMin("0ns"),
+				}),
+			},
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"consulclient_agent_self":       dataSourceConsulAgentSelf(),
-			"consulclient_catalog_nodes":    dataSourceConsulCatalogNodes(),
-			"consulclient_catalog_service":  dataSourceConsulCatalogService(),
-			"consulclient_catalog_services": dataSourceConsulCatalogServices(),
-			"consulclient_keys":             dataSourceConsulKeys(),
+			"consulclient_acl_policies":        dataSourceConsulACLPolicies(),
+			"consulclient_acl_policy":          dataSourceConsulACLPolicy(),
+			"consulclient_acl_replication":     dataSourceConsulACLReplication(),
+			"consulclient_acl_token_secret_id": dataSourceConsulACLTokenSecretID(),
+			"consulclient_acl_tokens":          dataSourceConsulACLTokens(),
+			"consulclient_agent_self":          dataSourceConsulAgentSelf(),
+			"consulclient_catalog_nodes":       dataSourceConsulCatalogNodes(),
+			"consulclient_catalog_service":     dataSourceConsulCatalogService(),
+			"consulclient_catalog_services":    dataSourceConsulCatalogServices(),
+			"consulclient_key_prefix":          dataSourceConsulKeyPrefix(),
+			"consulclient_keys":                dataSourceConsulKeys(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"consulclient_agent_service":  resourceConsulAgentService(),
-			"consulclient_catalog_entry":  resourceConsulCatalogEntry(),
-			"consulclient_keys":           resourceConsulKeys(),
-			"consulclient_key_prefix":     resourceConsulKeyPrefix(),
-			"consulclient_node":           resourceConsulNode(),
-			"consulclient_prepared_query": resourceConsulPreparedQuery(),
-			"consulclient_service":        resourceConsulService(),
-			"consulclient_acl":            resourceConsulAcl(),
+			"consulclient_agent_check":      resourceConsulAgentCheck(),
+			"consulclient_agent_service":    resourceConsulAgentService(),
+			"consulclient_catalog_entry":    resourceConsulCatalogEntry(),
+			"consulclient_keys":             resourceConsulKeys(),
+			"consulclient_key_prefix":       resourceConsulKeyPrefix(),
+			"consulclient_key_tree":         resourceConsulKeyTree(),
+			"consulclient_node":             resourceConsulNode(),
+			"consulclient_prepared_query":   resourceConsulPreparedQuery(),
+			"consulclient_service":          resourceConsulService(),
+			"consulclient_session":          resourceConsulSession(),
+			"consulclient_acl":              resourceConsulAcl(),
+			"consulclient_acl_auth_method":  resourceConsulACLAuthMethod(),
+			"consulclient_acl_binding_rule": resourceConsulACLBindingRule(),
+			"consulclient_acl_bootstrap":    resourceConsulACLBootstrap(),
+			"consulclient_acl_policy":       resourceConsulACLPolicy(),
+			"consulclient_acl_role":         resourceConsulACLRole(),
+			"consulclient_acl_token":        resourceConsulACLToken(),
 		},
 
 		ConfigureFunc: providerConfigure,
@@ -99,5 +142,22 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	if err := mapstructure.Decode(configRaw, &config); err != nil {
 		return nil, err
 	}
+	// The sources of the token may also be set through the environment.
+	var sources []string
+	for _, k := range []string{"token", "token_file", "token_command"} {
+		if d.Get(k).(string) != "" {
+			sources = append(sources, k)
+		}
+	}
+	if len(sources) > 1 {
+		return nil, fmt.Errorf("Only one of token, token_file and token_command can be set, including through the environment, got %s", strings.Join(sources, ", "))
+	}
+	ttl, err := time.ParseDuration(d.Get("token_command_ttl").(string))
+	if err != nil {
+		return nil, err
+	}
+	config.pool = newClientPool()
+	config.sessions = newSessionRenewer()
+	config.tokens = newTokenCache(ttl)
 	return &config, nil
 }
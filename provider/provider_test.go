@@ -0,0 +1,356 @@
+package provider
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+var testAccProviders map[string]terraform.ResourceProvider
+var testAccProvider *schema.Provider
+
+func init() {
+	testAccProvider = Provider().(*schema.Provider)
+	testAccProviders = map[string]terraform.ResourceProvider{
+		"consulclient": testAccProvider,
+	}
+}
+
+func TestProvider(t *testing.T) {
+	if err := Provider().(*schema.Provider).InternalValidate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestProvider_impl(t *testing.T) {
+	var _ terraform.ResourceProvider = Provider()
+}
+
+func TestProviderConfig_clientPool(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	config := &ProviderConfig{Host: consul.Address(), pool: newClientPool()}
+	first, err := config.NewClient()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	same := *config
+	second, err := same.NewClient()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if first != second {
+		t.Fatal("expected the same client for the same configuration")
+	}
+
+	other := *config
+	other.Token = "other"
+	third, err := other.NewClient()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if first == third {
+		t.Fatal("expected a different client for a different token")
+	}
+
+	if len(config.pool.transports) != 1 {
+		t.Fatalf("expected clients to share a single transport, got %d", len(config.pool.transports))
+	}
+
+	for _, c := range []*ProviderConfig{config, &other} {
+		client, _ := c.NewClient()
+		dc, err := config.pool.Datacenter(c, client)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if dc != "dc1" {
+			t.Fatalf("bad datacenter: %s", dc)
+		}
+	}
+	if len(config.pool.datacenters) != 1 {
+		t.Fatalf("expected a single datacenter lookup per endpoint, got %d", len(config.pool.datacenters))
+	}
+}
+
+func TestProviderConfigure_tokenSources(t *testing.T) {
+	for _, k := range []string{"CONSUL_TOKEN", "CONSUL_HTTP_TOKEN", "CONSUL_HTTP_TOKEN_FILE"} {
+		defer os.Setenv(k, os.Getenv(k))
+		os.Unsetenv(k)
+	}
+	p := Provider().(*schema.Provider)
+
+	for _, raw := range []map[string]interface{}{
+		{"token_file": "/run/consul/token"},
+		{"token_command": "cat /run/consul/token"},
+	} {
+		c, err := config.NewRawConfig(raw)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if _, es := p.Validate(terraform.NewResourceConfig(c)); len(es) > 0 {
+			t.Fatalf("unexpected errors for %v: %v", raw, es)
+		}
+		if _, err := providerConfigure(schema.TestResourceDataRaw(t, p.Schema, raw)); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	// The token given through the environment conflicts with the token
+	// file of the configuration.
+	os.Setenv("CONSUL_HTTP_TOKEN", "env")
+	d := schema.TestResourceDataRaw(t, p.Schema, map[string]interface{}{
+		"token_file": "/run/consul/token",
+	})
+	if _, err := providerConfigure(d); err == nil {
+		t.Fatal("expected an error for a token set along with a token file")
+	}
+}
+
+func TestProviderConfig_tokenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "token-file")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token")
+
+	config := &ProviderConfig{Token: "provider"}
+	d := schema.TestResourceDataRaw(t, resourceConsulKeys().Schema, map[string]interface{}{
+		"token_file": path,
+	})
+
+	// The file is read again each time, to pick up the rotated tokens.
+	for _, token := range []string{"first", "second"} {
+		if err := ioutil.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		resolved, _, err := config.GetResolvedConfig(d)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if resolved.Token != token {
+			t.Fatalf("bad token: %q, expected %q", resolved.Token, token)
+		}
+	}
+
+	// The token of the resource takes precedence over the one of the
+	// provider, whatever their sources.
+	config = &ProviderConfig{TokenFile: filepath.Join(dir, "missing")}
+	if _, _, err := config.GetResolvedConfig(d); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	d = schema.TestResourceDataRaw(t, resourceConsulKeys().Schema, map[string]interface{}{})
+	if _, _, err := config.GetResolvedConfig(d); err == nil {
+		t.Fatal("expected an error reading a missing token file")
+	}
+}
+
+func TestProviderConfig_tokenCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "token-command")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The command prints the number of times it has been run.
+	command := fmt.Sprintf("echo >> %s/runs && wc -l < %s/runs", dir, dir)
+	d := schema.TestResourceDataRaw(t, resourceConsulKeys().Schema, map[string]interface{}{
+		"token_command": command,
+	})
+
+	cases := []struct {
+		ttl    time.Duration
+		tokens []string
+	}{
+		{time.Hour, []string{"1", "1"}},
+		{0, []string{"2", "3"}},
+	}
+	for _, tc := range cases {
+		config := &ProviderConfig{tokens: newTokenCache(tc.ttl)}
+		for _, token := range tc.tokens {
+			resolved, _, err := config.GetResolvedConfig(d)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if resolved.Token != token {
+				t.Fatalf("bad token with a TTL of %s: %q, expected %q", tc.ttl, resolved.Token, token)
+			}
+		}
+	}
+
+	config := &ProviderConfig{TokenCommand: "exit 3"}
+	d = schema.TestResourceDataRaw(t, resourceConsulKeys().Schema, map[string]interface{}{})
+	if _, _, err := config.GetResolvedConfig(d); err == nil {
+		t.Fatal("expected an error running a failing token command")
+	}
+}
+
+func TestTokenCache_concurrentCommands(t *testing.T) {
+	dir, err := ioutil.TempDir("", "token-command")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(timeout time.Duration) { tokenCommandTimeout = timeout }(tokenCommandTimeout)
+	tokenCommandTimeout = 5 * time.Second
+
+	// Once started, the slow command waits for the fast one to have run,
+	// which it would time out doing if they could not run at the same
+	// time.
+	cache := newTokenCache(time.Hour)
+	slow := make(chan error, 1)
+	go func() {
+		token, err := cache.Command(fmt.Sprintf("touch %s/slow; while [ ! -f %s/fast ]; do sleep 0.01; done; echo slow", dir, dir))
+		if err == nil && token != "slow" {
+			err = fmt.Errorf("bad token: %q", token)
+		}
+		slow <- err
+	}()
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "slow")); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if token, err := cache.Command(fmt.Sprintf("touch %s/fast && echo fast", dir)); err != nil || token != "fast" {
+		t.Fatalf("bad token: %q, %v", token, err)
+	}
+	if err := <-slow; err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// A command that runs for too long is killed.
+	tokenCommandTimeout = 100 * time.Millisecond
+	start := time.Now()
+	_, err = cache.Command("sleep 10; echo late")
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("the token command was not killed, it ran for %s", elapsed)
+	}
+}
+
+func TestAccProvider_tokenSources(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	dir, err := ioutil.TempDir("", "token-sources")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	providerTokenFile := filepath.Join(dir, "provider")
+	resourceTokenFile := filepath.Join(dir, "resource")
+	if err := ioutil.WriteFile(providerTokenFile, []byte("provider-token"), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ioutil.WriteFile(resourceTokenFile, []byte("resource-token"), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul, "test/provider", "test/resource"),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccProviderConfig_tokenSources, consul.Address(), providerTokenFile, resourceTokenFile),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "test/provider", "one"),
+					testAccCheckConsulKeysValue(consul, "test/resource", "two"),
+					testAccCheckConsulTokensUsed(consul, "provider-token", "resource-token"),
+					resource.TestCheckResourceAttr("consulclient_keys.resource", "token_command", "cat "+resourceTokenFile),
+					testAccCheckStateDoesNotContain("consulclient_keys.provider", "provider-token"),
+					testAccCheckStateDoesNotContain("consulclient_keys.resource", "provider-token", "resource-token"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckConsulTokensUsed(consul *fakeConsul, tokens ...string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		for _, token := range tokens {
+			if !consul.tokens[token] {
+				return fmt.Errorf("No request was sent with the token %q", token)
+			}
+		}
+		return nil
+	}
+}
+
+const testAccProviderConfig_tokenSources = `
+provider "consulclient" {
+	host       = "%s"
+	token_file = "%s"
+}
+
+resource "consulclient_keys" "provider" {
+	key {
+		path   = "test/provider"
+		value  = "one"
+		delete = true
+	}
+}
+
+resource "consulclient_keys" "resource" {
+	token_command = "cat %s"
+
+	key {
+		path   = "test/resource"
+		value  = "two"
+		delete = true
+	}
+}
+`
+
+// testAccProviderConfig returns the configuration of a provider talking to
+// consul.
+func testAccProviderConfig(consul *fakeConsul) string {
+	return fmt.Sprintf(`
+provider "consulclient" {
+	host = "%s"
+}
+`, consul.Address())
+}
+
+// testAccProviderEnv points the provider at consul through the environment,
+// as import steps run without a configuration. The returned function
+// restores the environment. The environment is shared by the whole test
+// binary, so tests calling it must not run in parallel.
+func testAccProviderEnv(consul *fakeConsul) func() {
+	keys := []string{"CONSUL_ADDRESS", "CONSUL_HTTP_ADDR"}
+	old := make(map[string]string)
+	for _, k := range keys {
+		if v, ok := os.LookupEnv(k); ok {
+			old[k] = v
+		}
+		os.Unsetenv(k)
+	}
+	os.Setenv("CONSUL_HTTP_ADDR", consul.Address())
+
+	return func() {
+		for _, k := range keys {
+			if v, ok := old[k]; ok {
+				os.Setenv(k, v)
+			} else {
+				os.Unsetenv(k)
+			}
+		}
+	}
+}
@@ -72,7 +72,7 @@ var schemaQueryOpts = &schema.Schema{
 	},
 }
 
-func getQueryOpts(d *schema.ResourceData, client *consulapi.Client) (*consulapi.QueryOptions, error) {
+func getQueryOpts(d *schema.ResourceData, config *ProviderConfig, client *consulapi.Client) (*consulapi.QueryOptions, error) {
 	queryOpts := &consulapi.QueryOptions{}
 
 	if v, ok := d.GetOk(queryOptAllowStale); ok {
@@ -84,7 +84,7 @@ func getQueryOpts(d *schema.ResourceData, client *consulapi.Client) (*consulapi.
 	}
 
 	if queryOpts.Datacenter == "" {
-		dc, err := getDC(d, client)
+		dc, err := config.getDC(d, client)
 		if err != nil {
 			return nil, err
 		}
@@ -100,12 +100,7 @@ func getQueryOpts(d *schema.ResourceData, client *consulapi.Client) (*consulapi.
 	}
 
 	if v, ok := d.GetOk(queryOptNodeMeta); ok {
-		m := v.(map[string]interface{})
-		nodeMetaMap := make(map[string]string, len(queryOptNodeMeta))
-		for s, t := range m {
-			nodeMetaMap[s] = t.(string)
-		}
-		queryOpts.NodeMeta = nodeMetaMap
+		queryOpts.NodeMeta = expandStringMap(v)
 	}
 
 	if v, ok := d.GetOk(queryOptToken); ok {
@@ -123,3 +118,18 @@ func getQueryOpts(d *schema.ResourceData, client *consulapi.Client) (*consulapi.
 
 	return queryOpts, nil
 }
+
+// expandStringMap converts a map attribute into a map of strings, returning
+// nil for empty maps.
+func expandStringMap(v interface{}) map[string]string {
+	m := v.(map[string]interface{})
+	if len(m) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v.(string)
+	}
+	return result
+}
@@ -5,6 +5,12 @@ import (
 	"github.com/hashicorp/terraform/helper/schema"
 )
 
+// resourceConsulAclDeprecationMessage is shown for every use of the legacy
+// consulclient_acl resource. Resources cannot be deprecated as a whole in
+// this version of Terraform, so it is set on the attributes that describe
+// the token, of which any legacy ACL sets at least one.
+const resourceConsulAclDeprecationMessage = "consulclient_acl manages legacy ACL tokens, which Consul 1.4 replaced; use consulclient_acl_token and consulclient_acl_policy instead"
+
 func resourceConsulAcl() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceConsulAclCreate,
@@ -12,6 +18,10 @@ func resourceConsulAcl() *schema.Resource {
 		Read:   resourceConsulAclRead,
 		Delete: resourceConsulAclDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulImportState,
+		},
+
 		SchemaVersion: 1,
 
 		Schema: map[string]*schema.Schema{
@@ -54,10 +64,9 @@ func resourceConsulAcl() *schema.Resource {
 				ForceNew: true,
 			},
 
-			"token": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
 
 			"key": {
 				Type:     schema.TypeString,
@@ -67,18 +76,23 @@ func resourceConsulAcl() *schema.Resource {
 			},
 
 			"name": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:       schema.TypeString,
+				Optional:   true,
+				Deprecated: resourceConsulAclDeprecationMessage,
 			},
 
 			"type": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:       schema.TypeString,
+				Optional:   true,
+				Deprecated: resourceConsulAclDeprecationMessage,
 			},
 
 			"rules": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Deprecated:       resourceConsulAclDeprecationMessage,
+				ValidateFunc:     validateACLRules,
+				DiffSuppressFunc: suppressEquivalentACLRules,
 			},
 		},
 	}
@@ -95,12 +109,13 @@ func resourceConsulAclCreate(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 	acl := client.ACL()
-	dc, err := getDC(d, client)
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
 
-	aclClient := newACLClient(acl, dc, config.Token)
+	aclClient := newACLClient(acl, dc, token)
 
 	aclEntry := &consulapi.ACLEntry{
 		ID:    d.Get("key").(string),
@@ -134,12 +149,13 @@ func resourceConsulAclUpdate(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 	acl := client.ACL()
-	dc, err := getDC(d, client)
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
 
-	aclClient := newACLClient(acl, dc, config.Token)
+	aclClient := newACLClient(acl, dc, token)
 
 	aclEntry := &consulapi.ACLEntry{
 		ID:    d.Get("key").(string),
@@ -167,13 +183,14 @@ func resourceConsulAclRead(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 	acl := client.ACL()
+	token := resolvedConfig.Token
 
-	dc, err := getDC(d, client)
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
 
-	aclClient := newACLClient(acl, dc, config.Token)
+	aclClient := newACLClient(acl, dc, token)
 
 	aclEntry, err := aclClient.Read(d.Id())
 	if err != nil {
@@ -204,8 +221,8 @@ func resourceConsulAclDelete(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 	acl := client.ACL()
-	token := d.Get("token").(string)
-	dc, err := getDC(d, client)
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,290 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceConsulACLAuthMethod() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulACLAuthMethodCreate,
+		Update: resourceConsulACLAuthMethodUpdate,
+		Read:   resourceConsulACLAuthMethodRead,
+		Delete: resourceConsulACLAuthMethodDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulImportState,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"type": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"display_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"max_token_ttl": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentDurations,
+				ValidateFunc: makeValidationFunc("max_token_ttl", []interface{}{
+					validateDurationMin("0s"),
+				}),
+			},
+
+			// token_locality is the locality of the tokens created by
+			// logging in with the auth method, "local" when empty.
+			"token_locality": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: makeValidationFunc("token_locality", []interface{}{
+					validateRegexp(`^(local|global)?$`),
+				}),
+			},
+
+			// config is the configuration of the auth method, whose lists
+			// and maps are JSON-encoded. It is checked against the keys
+			// of the kubernetes, jwt and oidc types when applied, as the
+			// type is not known when config is validated.
+			"config": {
+				Type:             schema.TypeMap,
+				Optional:         true,
+				Sensitive:        true,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+		},
+	}
+}
+
+func resourceConsulACLAuthMethodCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	method, err := expandACLAuthMethod(d)
+	if err != nil {
+		return err
+	}
+	if err := aclClient.CreateAuthMethod(method); err != nil {
+		return err
+	}
+
+	d.SetId(method.Name)
+
+	return resourceConsulACLAuthMethodRead(d, meta)
+}
+
+func resourceConsulACLAuthMethodUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	method, err := expandACLAuthMethod(d)
+	if err != nil {
+		return err
+	}
+	if err := aclClient.UpdateAuthMethod(method); err != nil {
+		return err
+	}
+
+	return resourceConsulACLAuthMethodRead(d, meta)
+}
+
+func resourceConsulACLAuthMethodRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	method, err := aclClient.ReadAuthMethod(d.Id())
+	if err != nil {
+		return err
+	}
+	if method == nil {
+		log.Printf("[WARN] ACL auth method '%s' not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("name", method.Name)
+	d.Set("type", method.Type)
+	d.Set("display_name", method.DisplayName)
+	d.Set("description", method.Description)
+	d.Set("token_locality", method.TokenLocality)
+
+	maxTokenTTL := ""
+	if method.MaxTokenTTL != 0 {
+		maxTokenTTL = method.MaxTokenTTL.String()
+	}
+	d.Set("max_token_ttl", maxTokenTTL)
+
+	methodConfig, err := flattenACLAuthMethodConfig(method.Config)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("config", methodConfig); err != nil {
+		return err
+	}
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	return nil
+}
+
+func resourceConsulACLAuthMethodDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+	if err := aclClient.DeleteAuthMethod(d.Id()); err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func expandACLAuthMethod(d *schema.ResourceData) (*consulapi.ACLAuthMethod, error) {
+	method := &consulapi.ACLAuthMethod{
+		Name:          d.Get("name").(string),
+		Type:          d.Get("type").(string),
+		DisplayName:   d.Get("display_name").(string),
+		Description:   d.Get("description").(string),
+		TokenLocality: d.Get("token_locality").(string),
+	}
+
+	if v := d.Get("max_token_ttl").(string); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid max_token_ttl '%s': %v", v, err)
+		}
+		method.MaxTokenTTL = ttl
+	}
+
+	methodConfig, err := expandACLAuthMethodConfig(method.Type, d.Get("config").(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	method.Config = methodConfig
+
+	return method, nil
+}
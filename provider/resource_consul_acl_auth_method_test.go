@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulACLAuthMethod_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulACLAuthMethodDestroy(consul),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulACLAuthMethodConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulACLAuthMethodConfig(consul, "minikube", map[string]interface{}{
+						"Host":              "https://192.0.2.42:8443",
+						"CACert":            "-----BEGIN CERTIFICATE-----",
+						"ServiceAccountJWT": "eyJhbGciOiJSUzI1NiIsImtpZCI6IiJ9",
+					}),
+					resource.TestCheckResourceAttr("consulclient_acl_auth_method.minikube", "id", "minikube"),
+					resource.TestCheckResourceAttr("consulclient_acl_auth_method.minikube", "type", "kubernetes"),
+					resource.TestCheckResourceAttr("consulclient_acl_auth_method.minikube", "display_name", "Minikube"),
+					resource.TestCheckResourceAttr("consulclient_acl_auth_method.minikube", "max_token_ttl", "2m0s"),
+					resource.TestCheckResourceAttr("consulclient_acl_auth_method.minikube", "config.%", "3"),
+				),
+			},
+			{
+				// Durations and JSON written differently are not a change.
+				Config:   testAccProviderConfig(consul) + testAccConsulACLAuthMethodConfig,
+				PlanOnly: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulACLAuthMethodConfig_jwt,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulACLAuthMethodConfig(consul, "auth0", map[string]interface{}{
+						"JWKSURL":          "https://example.auth0.com/.well-known/jwks.json",
+						"BoundAudiences":   []interface{}{"consul"},
+						"ClaimMappings":    map[string]interface{}{"sub": "user"},
+						"ExpirationLeeway": "1m",
+					}),
+					resource.TestCheckResourceAttr("consulclient_acl_auth_method.auth0", "token_locality", "global"),
+				),
+			},
+			{
+				Config:   testAccProviderConfig(consul) + testAccConsulACLAuthMethodConfig_jwt,
+				PlanOnly: true,
+			},
+			{
+				ResourceName:      "consulclient_acl_auth_method.auth0",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config:      testAccProviderConfig(consul) + testAccConsulACLAuthMethodConfig_invalid,
+				ExpectError: regexp.MustCompile("Missing config keys for the kubernetes auth method: CACert, ServiceAccountJWT"),
+			},
+		},
+	})
+}
+
+func TestExpandACLAuthMethodConfig(t *testing.T) {
+	cases := []struct {
+		methodType string
+		raw        map[string]interface{}
+		config     map[string]interface{}
+		err        string
+	}{
+		{
+			methodType: "jwt",
+			raw: map[string]interface{}{
+				"JWTValidationPubKeys": `["key"]`,
+				"ClaimMappings":        `{"a": "b"}`,
+				"NotBeforeLeeway":      "5s",
+			},
+			config: map[string]interface{}{
+				"JWTValidationPubKeys": []interface{}{"key"},
+				"ClaimMappings":        map[string]interface{}{"a": "b"},
+				"NotBeforeLeeway":      "5s",
+			},
+		},
+		{
+			methodType: "kubernetes",
+			raw: map[string]interface{}{
+				"Host": "h", "CACert": "c", "ServiceAccountJWT": "j", "MapNamespaces": "true",
+			},
+			config: map[string]interface{}{
+				"Host": "h", "CACert": "c", "ServiceAccountJWT": "j", "MapNamespaces": true,
+			},
+		},
+		{
+			// The config of unknown types is passed through.
+			methodType: "aws-iam",
+			raw:        map[string]interface{}{"BoundIAMPrincipalARNs": `["arn"]`},
+			config:     map[string]interface{}{"BoundIAMPrincipalARNs": `["arn"]`},
+		},
+		{
+			methodType: "kubernetes",
+			raw:        map[string]interface{}{"Host": "h", "CACert": "c", "ServiceAccountJWT": "j", "Unknown": "x"},
+			err:        "Unsupported config key 'Unknown' for the kubernetes auth method",
+		},
+		{
+			methodType: "jwt",
+			raw:        map[string]interface{}{"JWKSURL": "u", "OIDCDiscoveryURL": "u"},
+			err:        "Exactly one of JWTValidationPubKeys, JWKSURL and OIDCDiscoveryURL must be set for the jwt auth method",
+		},
+		{
+			methodType: "jwt",
+			raw:        map[string]interface{}{"JWKSURL": "u", "BoundAudiences": "consul"},
+			err:        "Invalid config key 'BoundAudiences' for the jwt auth method: expected a JSON-encoded list: invalid character 'c' looking for beginning of value",
+		},
+		{
+			methodType: "oidc",
+			raw:        map[string]interface{}{"OIDCDiscoveryURL": "u", "VerboseOIDCLogging": "yes"},
+			err:        `Invalid config key 'VerboseOIDCLogging' for the oidc auth method: "yes" is not a boolean`,
+		},
+	}
+
+	for i, tc := range cases {
+		config, err := expandACLAuthMethodConfig(tc.methodType, tc.raw)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Fatalf("%d: expected error %q, got %v", i, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(config, tc.config) {
+			t.Fatalf("%d: bad config: %#v", i, config)
+		}
+	}
+}
+
+func testAccCheckConsulACLAuthMethodConfig(consul *fakeConsul, name string, config map[string]interface{}) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		method, ok := consul.aclAuthMethods[name]
+		if !ok {
+			return fmt.Errorf("ACL auth method '%s' does not exist", name)
+		}
+		if !reflect.DeepEqual(method.Config, config) {
+			return fmt.Errorf("ACL auth method '%s' has config %#v; want %#v", name, method.Config, config)
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulACLAuthMethodDestroy(consul *fakeConsul) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		if len(consul.aclAuthMethods) != 0 {
+			return fmt.Errorf("%d ACL auth methods still exist", len(consul.aclAuthMethods))
+		}
+		return nil
+	}
+}
+
+const testAccConsulACLAuthMethodConfig = `
+resource "consulclient_acl_auth_method" "minikube" {
+	name          = "minikube"
+	type          = "kubernetes"
+	display_name  = "Minikube"
+	max_token_ttl = "2m"
+
+	config {
+		Host              = "https://192.0.2.42:8443"
+		CACert            = "-----BEGIN CERTIFICATE-----"
+		ServiceAccountJWT = "eyJhbGciOiJSUzI1NiIsImtpZCI6IiJ9"
+	}
+}
+`
+
+const testAccConsulACLAuthMethodConfig_jwt = `
+resource "consulclient_acl_auth_method" "auth0" {
+	name           = "auth0"
+	type           = "jwt"
+	token_locality = "global"
+
+	config {
+		JWKSURL          = "https://example.auth0.com/.well-known/jwks.json"
+		BoundAudiences   = "[ \"consul\" ]"
+		ClaimMappings    = "{ \"sub\": \"user\" }"
+		ExpirationLeeway = "1m"
+	}
+}
+`
+
+const testAccConsulACLAuthMethodConfig_invalid = `
+resource "consulclient_acl_auth_method" "auth0" {
+	name           = "auth0"
+	type           = "jwt"
+	token_locality = "global"
+
+	config {
+		JWKSURL          = "https://example.auth0.com/.well-known/jwks.json"
+		BoundAudiences   = "[ \"consul\" ]"
+		ClaimMappings    = "{ \"sub\": \"user\" }"
+		ExpirationLeeway = "1m"
+	}
+}
+
+resource "consulclient_acl_auth_method" "broken" {
+	name = "broken"
+	type = "kubernetes"
+
+	config {
+		Host = "https://192.0.2.42:8443"
+	}
+}
+`
@@ -0,0 +1,238 @@
+package provider
+
+import (
+	"log"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceConsulACLBindingRule() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulACLBindingRuleCreate,
+		Update: resourceConsulACLBindingRuleUpdate,
+		Read:   resourceConsulACLBindingRuleRead,
+		Delete: resourceConsulACLBindingRuleDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulImportState,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			"auth_method": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// selector is the expression matching the identities, as
+			// verified by the auth method, the rule applies to. The rule
+			// applies to all of them when empty.
+			"selector": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// bind_type is either "service", to grant the tokens the
+			// service identity of bind_name, or "role", to link them to
+			// the role named bind_name.
+			"bind_type": {
+				Type:     schema.TypeString,
+				Required: true,
+				ValidateFunc: makeValidationFunc("bind_type", []interface{}{
+					validateRegexp(`^(service|role)$`),
+				}),
+			},
+
+			// bind_name may be templated with the fields of the
+			// identities, as in "${serviceaccount.name}".
+			"bind_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		},
+	}
+}
+
+func resourceConsulACLBindingRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	rule := expandACLBindingRule(d)
+	if err := aclClient.CreateBindingRule(rule); err != nil {
+		return err
+	}
+
+	d.SetId(rule.ID)
+
+	return resourceConsulACLBindingRuleRead(d, meta)
+}
+
+func resourceConsulACLBindingRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	rule := expandACLBindingRule(d)
+	rule.ID = d.Id()
+	if err := aclClient.UpdateBindingRule(rule); err != nil {
+		return err
+	}
+
+	return resourceConsulACLBindingRuleRead(d, meta)
+}
+
+func resourceConsulACLBindingRuleRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	rule, err := aclClient.ReadBindingRule(d.Id())
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		log.Printf("[WARN] ACL binding rule '%s' not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("auth_method", rule.AuthMethod)
+	d.Set("description", rule.Description)
+	d.Set("selector", rule.Selector)
+	d.Set("bind_type", string(rule.BindType))
+	d.Set("bind_name", rule.BindName)
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	return nil
+}
+
+func resourceConsulACLBindingRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+	if err := aclClient.DeleteBindingRule(d.Id()); err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func expandACLBindingRule(d *schema.ResourceData) *consulapi.ACLBindingRule {
+	return &consulapi.ACLBindingRule{
+		AuthMethod:  d.Get("auth_method").(string),
+		Description: d.Get("description").(string),
+		Selector:    d.Get("selector").(string),
+		BindType:    consulapi.BindingRuleBindType(d.Get("bind_type").(string)),
+		BindName:    d.Get("bind_name").(string),
+	}
+}
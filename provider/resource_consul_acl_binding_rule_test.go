@@ -0,0 +1,224 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulACLBindingRule_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	consul.lock.Lock()
+	consul.aclLoginIdentities["web-jwt"] = map[string]string{
+		"serviceaccount.namespace": "default",
+		"serviceaccount.name":      "web",
+	}
+	consul.aclLoginIdentities["api-jwt"] = map[string]string{
+		"serviceaccount.namespace": "default",
+		"serviceaccount.name":      "api",
+	}
+	consul.aclLoginIdentities["ops-jwt"] = map[string]string{
+		"serviceaccount.namespace": "ops",
+		"serviceaccount.name":      "admin",
+	}
+	consul.lock.Unlock()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulACLBindingRuleDestroy(consul),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulACLBindingRuleConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulclient_acl_binding_rule.services", "auth_method", "minikube"),
+					resource.TestCheckResourceAttr("consulclient_acl_binding_rule.services", "bind_type", "service"),
+					resource.TestCheckResourceAttr("consulclient_acl_binding_rule.services", "bind_name", "${serviceaccount.name}"),
+					testAccCheckConsulACLLogin(consul, "minikube", "web-jwt", func(token *consulapi.ACLToken) error {
+						if len(token.ServiceIdentities) != 1 || token.ServiceIdentities[0].ServiceName != "web" {
+							return fmt.Errorf("Bad service identities: %#v", token.ServiceIdentities)
+						}
+						if len(token.Roles) != 0 {
+							return fmt.Errorf("Bad roles: %#v", token.Roles)
+						}
+						return nil
+					}),
+					testAccCheckConsulACLLogin(consul, "minikube", "ops-jwt", func(token *consulapi.ACLToken) error {
+						if len(token.Roles) != 1 || token.Roles[0].Name != "ops" {
+							return fmt.Errorf("Bad roles: %#v", token.Roles)
+						}
+						return nil
+					}),
+					testAccCheckConsulACLLogin(consul, "minikube", "unknown-jwt", nil),
+				),
+			},
+			{
+				// Once the selector is narrowed, the identities it no
+				// longer matches cannot log in, while the ones matched by
+				// other rules still can.
+				Config: testAccProviderConfig(consul) + testAccConsulACLBindingRuleConfig_update,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulclient_acl_binding_rule.services", "description", "Default services"),
+					resource.TestCheckResourceAttr("consulclient_acl_binding_rule.services", "selector", "serviceaccount.namespace==\"default\" and serviceaccount.name==\"api\""),
+					testAccCheckConsulACLLogin(consul, "minikube", "api-jwt", func(token *consulapi.ACLToken) error {
+						if len(token.ServiceIdentities) != 1 || token.ServiceIdentities[0].ServiceName != "svc-api" {
+							return fmt.Errorf("Bad service identities: %#v", token.ServiceIdentities)
+						}
+						return nil
+					}),
+					testAccCheckConsulACLLogin(consul, "minikube", "web-jwt", nil),
+					testAccCheckConsulACLLogin(consul, "minikube", "ops-jwt", func(token *consulapi.ACLToken) error {
+						if len(token.Roles) != 1 || token.Roles[0].Name != "ops" {
+							return fmt.Errorf("Bad roles: %#v", token.Roles)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				// Without the rule binding it, the ops role is no longer
+				// granted.
+				Config: testAccProviderConfig(consul) + testAccConsulACLBindingRuleConfig_remove,
+				Check:  testAccCheckConsulACLLogin(consul, "minikube", "ops-jwt", nil),
+			},
+			{
+				ResourceName:      "consulclient_acl_binding_rule.services",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// testAccCheckConsulACLLogin logs in with the given auth method and bearer
+// token, and checks the token returned with check, or that the login is
+// denied if check is nil.
+func testAccCheckConsulACLLogin(consul *fakeConsul, authMethod, bearerToken string, check func(*consulapi.ACLToken) error) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		config := consulapi.DefaultConfig()
+		config.Address = consul.Address()
+		client, err := consulapi.NewClient(config)
+		if err != nil {
+			return err
+		}
+
+		token, _, err := client.ACL().Login(&consulapi.ACLLoginParams{
+			AuthMethod:  authMethod,
+			BearerToken: bearerToken,
+		}, nil)
+		if check == nil {
+			if err == nil {
+				return fmt.Errorf("Login with '%s' should have been denied", bearerToken)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Login with '%s' failed: %v", bearerToken, err)
+		}
+		if token.AuthMethod != authMethod {
+			return fmt.Errorf("Token created by auth method '%s'; want '%s'", token.AuthMethod, authMethod)
+		}
+		return check(token)
+	}
+}
+
+func testAccCheckConsulACLBindingRuleDestroy(consul *fakeConsul) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		if len(consul.aclBindingRules) != 0 {
+			return fmt.Errorf("%d ACL binding rules still exist", len(consul.aclBindingRules))
+		}
+		return nil
+	}
+}
+
+const testAccConsulACLBindingRuleConfig = `
+resource "consulclient_acl_auth_method" "minikube" {
+	name = "minikube"
+	type = "kubernetes"
+
+	config {
+		Host              = "https://192.0.2.42:8443"
+		CACert            = "-----BEGIN CERTIFICATE-----"
+		ServiceAccountJWT = "eyJhbGciOiJSUzI1NiIsImtpZCI6IiJ9"
+	}
+}
+
+resource "consulclient_acl_role" "ops" {
+	name = "ops"
+}
+
+resource "consulclient_acl_binding_rule" "services" {
+	auth_method = "${consulclient_acl_auth_method.minikube.name}"
+	selector    = "serviceaccount.namespace==\"default\""
+	bind_type   = "service"
+	bind_name   = "$${serviceaccount.name}"
+}
+
+resource "consulclient_acl_binding_rule" "ops" {
+	auth_method = "${consulclient_acl_auth_method.minikube.name}"
+	selector    = "serviceaccount.namespace==\"ops\""
+	bind_type   = "role"
+	bind_name   = "${consulclient_acl_role.ops.name}"
+}
+`
+
+const testAccConsulACLBindingRuleConfig_update = `
+resource "consulclient_acl_auth_method" "minikube" {
+	name = "minikube"
+	type = "kubernetes"
+
+	config {
+		Host              = "https://192.0.2.42:8443"
+		CACert            = "-----BEGIN CERTIFICATE-----"
+		ServiceAccountJWT = "eyJhbGciOiJSUzI1NiIsImtpZCI6IiJ9"
+	}
+}
+
+resource "consulclient_acl_role" "ops" {
+	name = "ops"
+}
+
+resource "consulclient_acl_binding_rule" "ops" {
+	auth_method = "${consulclient_acl_auth_method.minikube.name}"
+	selector    = "serviceaccount.namespace==\"ops\""
+	bind_type   = "role"
+	bind_name   = "${consulclient_acl_role.ops.name}"
+}
+
+resource "consulclient_acl_binding_rule" "services" {
+	auth_method = "${consulclient_acl_auth_method.minikube.name}"
+	description = "Default services"
+	selector    = "serviceaccount.namespace==\"default\" and serviceaccount.name==\"api\""
+	bind_type   = "service"
+	bind_name   = "svc-$${serviceaccount.name}"
+}
+`
+
+const testAccConsulACLBindingRuleConfig_remove = `
+resource "consulclient_acl_auth_method" "minikube" {
+	name = "minikube"
+	type = "kubernetes"
+
+	config {
+		Host              = "https://192.0.2.42:8443"
+		CACert            = "-----BEGIN CERTIFICATE-----"
+		ServiceAccountJWT = "eyJhbGciOiJSUzI1NiIsImtpZCI6IiJ9"
+	}
+}
+
+resource "consulclient_acl_binding_rule" "services" {
+	auth_method = "${consulclient_acl_auth_method.minikube.name}"
+	description = "Default services"
+	selector    = "serviceaccount.namespace==\"default\" and serviceaccount.name==\"api\""
+	bind_type   = "service"
+	bind_name   = "svc-$${serviceaccount.name}"
+}
+`
@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceConsulACLBootstrap() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulACLBootstrapCreate,
+		Update: resourceConsulACLBootstrapUpdate,
+		Read:   resourceConsulACLBootstrapRead,
+		Delete: resourceConsulACLBootstrapDelete,
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			// reset_file is the path of the acl-bootstrap-reset file in
+			// the data directory of the leader, as seen by Terraform. When
+			// set, an ACL system that was already bootstrapped is reset by
+			// writing the reset index given by Consul to it.
+			"reset_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			// accessor_id is also the ID of the resource, and is not a
+			// secret: only secret_id grants access.
+			"accessor_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"secret_id": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func resourceConsulACLBootstrapCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+
+	// The ACL system is bootstrapped through the agent of the resource,
+	// before its datacenter can be looked up with the new token.
+	aclClient := newACLClient(client.ACL(), "", "")
+
+	aclToken, err := aclClient.Bootstrap()
+	if notAllowed, ok := err.(*aclBootstrapNotAllowedError); ok {
+		resetFile := d.Get("reset_file").(string)
+		if resetFile == "" {
+			return fmt.Errorf(
+				"The ACL system of %s has already been bootstrapped. To bootstrap it again, "+
+					"write the reset index %d to the acl-bootstrap-reset file in the data "+
+					"directory of the leader, or set reset_file to the path of that file",
+				resolvedConfig.Host, notAllowed.ResetIndex,
+			)
+		}
+
+		log.Printf("[WARN] Resetting the bootstrapped ACL system with reset index %d", notAllowed.ResetIndex)
+		resetIndex := strconv.FormatUint(notAllowed.ResetIndex, 10)
+		if err := ioutil.WriteFile(resetFile, []byte(resetIndex), 0600); err != nil {
+			return fmt.Errorf("Failed to write ACL bootstrap reset file '%s': %v", resetFile, err)
+		}
+		aclToken, err = aclClient.Bootstrap()
+	}
+	if err != nil {
+		return err
+	}
+
+	d.SetId(aclToken.AccessorID)
+	d.Set("accessor_id", aclToken.AccessorID)
+	d.Set("secret_id", aclToken.SecretID)
+
+	return resourceConsulACLBootstrapRead(d, meta)
+}
+
+func resourceConsulACLBootstrapUpdate(d *schema.ResourceData, meta interface{}) error {
+	// Only the connection settings can change in place.
+	return resourceConsulACLBootstrapRead(d, meta)
+}
+
+func resourceConsulACLBootstrapRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+
+	// The token is read with its own secret, as the token of the provider
+	// may not exist yet or may have been created with it.
+	resolvedConfig.Token = d.Get("secret_id").(string)
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, "")
+
+	aclToken, err := aclClient.ReadToken(d.Id())
+	if err != nil {
+		return err
+	}
+	if aclToken == nil {
+		log.Printf("[WARN] ACL bootstrap token '%s' not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("accessor_id", aclToken.AccessorID)
+	d.Set("secret_id", aclToken.SecretID)
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	return nil
+}
+
+// resourceConsulACLBootstrapDelete only removes the bootstrap token from the
+// state: an ACL system cannot be unbootstrapped, and deleting its
+// management token could leave it without any.
+func resourceConsulACLBootstrapDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[WARN] Removing ACL bootstrap token '%s' from state; it is left in Consul", d.Id())
+	d.SetId("")
+	return nil
+}
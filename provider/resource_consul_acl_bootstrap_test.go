@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulACLBootstrap_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulACLBootstrapConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulACLBootstrapToken(consul, "consulclient_acl_bootstrap.cluster"),
+					resource.TestCheckResourceAttr("consulclient_acl_bootstrap.cluster", "datacenter", "dc1"),
+				),
+			},
+		},
+	})
+
+	// The ACL system cannot be bootstrapped again without a reset.
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderConfig(consul) + testAccConsulACLBootstrapConfig,
+				ExpectError: regexp.MustCompile(`already been bootstrapped.*reset index [0-9]+`),
+			},
+		},
+	})
+}
+
+func TestAccConsulACLBootstrap_reset(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	dir, err := ioutil.TempDir("", "acl-bootstrap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	resetFile := filepath.Join(dir, "acl-bootstrap-reset")
+
+	consul.lock.Lock()
+	consul.aclResetIndex = 42
+	consul.aclResetFile = resetFile
+	consul.lock.Unlock()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulACLBootstrapConfig_reset, resetFile),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulACLBootstrapToken(consul, "consulclient_acl_bootstrap.cluster"),
+					func(*terraform.State) error {
+						b, err := ioutil.ReadFile(resetFile)
+						if err != nil {
+							return err
+						}
+						if string(b) != "42" {
+							return fmt.Errorf("Bad reset file content %q", b)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckConsulACLBootstrapToken(consul *fakeConsul, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		token, ok := consul.aclTokens[rs.Primary.ID]
+		if !ok {
+			return fmt.Errorf("ACL token '%s' does not exist", rs.Primary.ID)
+		}
+		if rs.Primary.Attributes["accessor_id"] != token.AccessorID {
+			return fmt.Errorf("Bad accessor ID %q", rs.Primary.Attributes["accessor_id"])
+		}
+		if rs.Primary.Attributes["secret_id"] != token.SecretID {
+			return fmt.Errorf("Bad secret ID %q", rs.Primary.Attributes["secret_id"])
+		}
+		return nil
+	}
+}
+
+const testAccConsulACLBootstrapConfig = `
+resource "consulclient_acl_bootstrap" "cluster" {}
+`
+
+const testAccConsulACLBootstrapConfig_reset = `
+resource "consulclient_acl_bootstrap" "cluster" {
+	reset_file = "%s"
+}
+`
@@ -0,0 +1,230 @@
+package provider
+
+import (
+	"log"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceConsulACLPolicy() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulACLPolicyCreate,
+		Update: resourceConsulACLPolicyUpdate,
+		Read:   resourceConsulACLPolicyRead,
+		Delete: resourceConsulACLPolicyDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulImportState,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"rules": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validateACLRules,
+				DiffSuppressFunc: suppressEquivalentACLRules,
+			},
+
+			// datacenters restricts the policy to the given datacenters;
+			// it is valid in all of them when empty.
+			"datacenters": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+		},
+	}
+}
+
+func resourceConsulACLPolicyCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	policy := expandACLPolicy(d)
+	if err := aclClient.CreatePolicy(policy); err != nil {
+		return err
+	}
+
+	d.SetId(policy.ID)
+
+	return resourceConsulACLPolicyRead(d, meta)
+}
+
+func resourceConsulACLPolicyUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	policy := expandACLPolicy(d)
+	policy.ID = d.Id()
+	if err := aclClient.UpdatePolicy(policy); err != nil {
+		return err
+	}
+
+	return resourceConsulACLPolicyRead(d, meta)
+}
+
+func resourceConsulACLPolicyRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	policy, err := aclClient.ReadPolicy(d.Id())
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		log.Printf("[WARN] ACL policy '%s' not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("name", policy.Name)
+	d.Set("description", policy.Description)
+	d.Set("rules", policy.Rules)
+	if err := d.Set("datacenters", policy.Datacenters); err != nil {
+		return err
+	}
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	return nil
+}
+
+func resourceConsulACLPolicyDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+	if err := aclClient.DeletePolicy(d.Id()); err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func expandACLPolicy(d *schema.ResourceData) *consulapi.ACLPolicy {
+	policy := &consulapi.ACLPolicy{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		Rules:       d.Get("rules").(string),
+	}
+	for _, dc := range d.Get("datacenters").(*schema.Set).List() {
+		policy.Datacenters = append(policy.Datacenters, dc.(string))
+	}
+	return policy
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulACLPolicy_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulACLPolicyDestroy(consul),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulACLPolicyConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulACLPolicyRules(consul, "consulclient_acl_policy.app", `key_prefix "app/" { policy = "read" }`),
+					resource.TestCheckResourceAttr("consulclient_acl_policy.app", "name", "app"),
+					resource.TestCheckResourceAttr("consulclient_acl_policy.app", "description", "Application policy"),
+					resource.TestCheckResourceAttr("consulclient_acl_policy.app", "datacenters.#", "1"),
+					resource.TestCheckResourceAttr("consulclient_acl_policy.app", "datacenter", "dc1"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulACLPolicyConfig_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulACLPolicyRules(consul, "consulclient_acl_policy.app", `key_prefix "app/" { policy = "write" }`),
+					resource.TestCheckResourceAttr("consulclient_acl_policy.app", "name", "app-rw"),
+					resource.TestCheckResourceAttr("consulclient_acl_policy.app", "datacenters.#", "0"),
+				),
+			},
+			{
+				ResourceName:      "consulclient_acl_policy.app",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckConsulACLPolicyRules(consul *fakeConsul, name, rules string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		policy, ok := consul.aclPolicies[rs.Primary.ID]
+		if !ok {
+			return fmt.Errorf("ACL policy '%s' does not exist", rs.Primary.ID)
+		}
+		if policy.Rules != rules {
+			return fmt.Errorf("ACL policy '%s' has rules %q; want %q", rs.Primary.ID, policy.Rules, rules)
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulACLPolicyDestroy(consul *fakeConsul) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		if len(consul.aclPolicies) != 0 {
+			return fmt.Errorf("%d ACL policies still exist", len(consul.aclPolicies))
+		}
+		return nil
+	}
+}
+
+const testAccConsulACLPolicyConfig = `
+resource "consulclient_acl_policy" "app" {
+	name        = "app"
+	description = "Application policy"
+	rules       = "key_prefix \"app/\" { policy = \"read\" }"
+	datacenters = ["dc1"]
+}
+`
+
+const testAccConsulACLPolicyConfig_update = `
+resource "consulclient_acl_policy" "app" {
+	name        = "app-rw"
+	description = "Application policy"
+	rules       = "key_prefix \"app/\" { policy = \"write\" }"
+}
+`
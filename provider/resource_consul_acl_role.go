@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"log"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceConsulACLRole() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulACLRoleCreate,
+		Update: resourceConsulACLRoleUpdate,
+		Read:   resourceConsulACLRoleRead,
+		Delete: resourceConsulACLRoleDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulImportState,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// policies are the names of the policies linked to the role.
+			"policies": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+
+			"service_identities": aclServiceIdentitiesSchema(),
+		},
+	}
+}
+
+func resourceConsulACLRoleCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	role := expandACLRole(d)
+	if err := aclClient.CreateRole(role); err != nil {
+		return err
+	}
+
+	d.SetId(role.ID)
+
+	return resourceConsulACLRoleRead(d, meta)
+}
+
+func resourceConsulACLRoleUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	role := expandACLRole(d)
+	role.ID = d.Id()
+	if err := aclClient.UpdateRole(role); err != nil {
+		return err
+	}
+
+	return resourceConsulACLRoleRead(d, meta)
+}
+
+func resourceConsulACLRoleRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	role, err := aclClient.ReadRole(d.Id())
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		log.Printf("[WARN] ACL role '%s' not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("name", role.Name)
+	d.Set("description", role.Description)
+	if err := d.Set("policies", flattenACLLinks(role.Policies)); err != nil {
+		return err
+	}
+	if err := d.Set("service_identities", flattenACLServiceIdentities(role.ServiceIdentities)); err != nil {
+		return err
+	}
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	return nil
+}
+
+func resourceConsulACLRoleDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+	if err := aclClient.DeleteRole(d.Id()); err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func expandACLRole(d *schema.ResourceData) *consulapi.ACLRole {
+	return &consulapi.ACLRole{
+		Name:              d.Get("name").(string),
+		Description:       d.Get("description").(string),
+		Policies:          expandACLLinks(d.Get("policies").(*schema.Set)),
+		ServiceIdentities: expandACLServiceIdentities(d.Get("service_identities").([]interface{})),
+	}
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulACLRole_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulACLRoleDestroy(consul),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulACLRoleConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulclient_acl_role.app", "name", "app"),
+					resource.TestCheckResourceAttr("consulclient_acl_role.app", "policies.#", "1"),
+					resource.TestCheckResourceAttr("consulclient_acl_role.app", "service_identities.#", "1"),
+					resource.TestCheckResourceAttr("consulclient_acl_role.app", "service_identities.0.service_name", "web"),
+					resource.TestCheckResourceAttr("consulclient_acl_role.app", "service_identities.0.datacenters.#", "1"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulACLRoleConfig_update,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulclient_acl_role.app", "description", "Application role"),
+					resource.TestCheckResourceAttr("consulclient_acl_role.app", "policies.#", "2"),
+					resource.TestCheckResourceAttr("consulclient_acl_role.app", "service_identities.#", "0"),
+				),
+			},
+			{
+				ResourceName:      "consulclient_acl_role.app",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckConsulACLRoleDestroy(consul *fakeConsul) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		if len(consul.aclRoles) != 0 {
+			return fmt.Errorf("%d ACL roles still exist", len(consul.aclRoles))
+		}
+		return nil
+	}
+}
+
+const testAccConsulACLRoleConfig = `
+resource "consulclient_acl_policy" "read" {
+	name  = "read"
+	rules = "key_prefix \"\" { policy = \"read\" }"
+}
+
+resource "consulclient_acl_role" "app" {
+	name     = "app"
+	policies = ["${consulclient_acl_policy.read.name}"]
+
+	service_identities {
+		service_name = "web"
+		datacenters  = ["dc1"]
+	}
+}
+`
+
+const testAccConsulACLRoleConfig_update = `
+resource "consulclient_acl_policy" "read" {
+	name  = "read"
+	rules = "key_prefix \"\" { policy = \"read\" }"
+}
+
+resource "consulclient_acl_policy" "write" {
+	name  = "write"
+	rules = "key_prefix \"app/\" { policy = \"write\" }"
+}
+
+resource "consulclient_acl_role" "app" {
+	name        = "app"
+	description = "Application role"
+	policies    = ["${consulclient_acl_policy.read.name}", "${consulclient_acl_policy.write.name}"]
+}
+`
@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulAcl_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulAclDestroy(consul),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulAclConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulAclRules(consul, "consulclient_acl.app", `key "" { policy = "read" }`),
+					resource.TestCheckResourceAttrSet("consulclient_acl.app", "key"),
+					resource.TestCheckResourceAttr("consulclient_acl.app", "name", "app"),
+					resource.TestCheckResourceAttr("consulclient_acl.app", "type", "client"),
+					resource.TestCheckResourceAttr("consulclient_acl.app", "datacenter", "dc1"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulAclConfig_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulAclRules(consul, "consulclient_acl.app", `key "" { policy = "write" }`),
+				),
+			},
+			{
+				// The same rules in JSON are not a change.
+				Config:   testAccProviderConfig(consul) + testAccConsulAclConfig_json,
+				PlanOnly: true,
+			},
+			{
+				Config:      testAccProviderConfig(consul) + testAccConsulAclConfig_invalid,
+				ExpectError: regexp.MustCompile("Line 3: invalid policy 'wirte' for 'key'"),
+			},
+			{
+				ResourceName:      "consulclient_acl.app",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulAcl_token(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulAclDestroy(consul),
+		Steps: []resource.TestStep{
+			{
+				// The token of the resource is used rather than the one of
+				// the provider.
+				Config: fmt.Sprintf(testAccConsulAclConfig_token, consul.Address()),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulAclRules(consul, "consulclient_acl.app", `key "" { policy = "read" }`),
+					testAccCheckConsulTokensUsed(consul, "acl-token"),
+					func(*terraform.State) error {
+						consul.lock.Lock()
+						defer consul.lock.Unlock()
+
+						if consul.tokens["provider-token"] {
+							return fmt.Errorf("A request was sent with the token of the provider")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestResourceConsulAcl_deprecated(t *testing.T) {
+	raw, err := config.NewRawConfig(map[string]interface{}{
+		"name":  "test",
+		"rules": `key "" { policy = "read" }`,
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	ws, es := resourceConsulAcl().Validate(terraform.NewResourceConfig(raw))
+	if len(es) > 0 {
+		t.Fatalf("unexpected errors: %v", es)
+	}
+	if len(ws) == 0 || !strings.Contains(ws[0], "consulclient_acl_token") {
+		t.Fatalf("expected a deprecation warning, got %v", ws)
+	}
+}
+
+func testAccCheckConsulAclRules(consul *fakeConsul, name, rules string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		acl, ok := consul.acls[rs.Primary.ID]
+		if !ok {
+			return fmt.Errorf("ACL '%s' does not exist", rs.Primary.ID)
+		}
+		if acl.Rules != rules {
+			return fmt.Errorf("ACL '%s' has rules %q; want %q", rs.Primary.ID, acl.Rules, rules)
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulAclDestroy(consul *fakeConsul) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		if len(consul.acls) != 0 {
+			return fmt.Errorf("%d ACLs still exist", len(consul.acls))
+		}
+		return nil
+	}
+}
+
+const testAccConsulAclConfig = `
+resource "consulclient_acl" "app" {
+	name  = "app"
+	type  = "client"
+	rules = "key \"\" { policy = \"read\" }"
+}
+`
+
+const testAccConsulAclConfig_token = `
+provider "consulclient" {
+	host  = "%s"
+	token = "provider-token"
+}
+
+resource "consulclient_acl" "app" {
+	token_command = "echo acl-token"
+
+	name  = "app"
+	type  = "client"
+	rules = "key \"\" { policy = \"read\" }"
+}
+`
+
+const testAccConsulAclConfig_update = `
+resource "consulclient_acl" "app" {
+	name  = "app"
+	type  = "client"
+	rules = "key \"\" { policy = \"write\" }"
+}
+`
+
+const testAccConsulAclConfig_json = `
+resource "consulclient_acl" "app" {
+	name  = "app"
+	type  = "client"
+	rules = <<EOF
+{
+  "key": {
+    "": {
+      "policy": "write"
+    }
+  }
+}
+EOF
+}
+`
+
+const testAccConsulAclConfig_invalid = `
+resource "consulclient_acl" "app" {
+	name  = "app"
+	type  = "client"
+	rules = <<EOF
+
+key "" {
+  policy = "wirte"
+}
+EOF
+}
+`
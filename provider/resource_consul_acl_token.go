@@ -0,0 +1,381 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceConsulACLToken() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulACLTokenCreate,
+		Update: resourceConsulACLTokenUpdate,
+		Read:   resourceConsulACLTokenRead,
+		Delete: resourceConsulACLTokenDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulImportState,
+		},
+
+		// The secret ID of the token is not stored in the state; it can be
+		// read with the consulclient_acl_token_secret_id data source.
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			"accessor_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			// policies and roles are the names of the policies and roles
+			// linked to the token.
+			"policies": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+
+			"roles": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+
+			"service_identities": aclServiceIdentitiesSchema(),
+
+			// local tokens are only valid in the datacenter they are
+			// created in, and are not replicated.
+			"local": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				ForceNew: true,
+			},
+
+			// expiration_ttl is not returned by Consul, so it is read as
+			// the time between the creation and the expiration of the
+			// token, as for imported tokens.
+			"expiration_ttl": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressEquivalentDurations,
+				ValidateFunc: makeValidationFunc("expiration_ttl", []interface{}{
+					validateDurationMin("1m"),
+				}),
+			},
+
+			"expiration_time": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// aclServiceIdentitiesSchema returns the schema of the service identities of
+// tokens and roles, which grant the privileges needed by the instances of
+// the named services.
+func aclServiceIdentitiesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"service_name": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+
+				"datacenters": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+					Set:      schema.HashString,
+				},
+			},
+		},
+	}
+}
+
+func resourceConsulACLTokenCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	aclToken := expandACLToken(d)
+	aclToken.AccessorID = d.Get("accessor_id").(string)
+	aclToken.Local = d.Get("local").(bool)
+	if v := d.Get("expiration_ttl").(string); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("Invalid expiration_ttl '%s': %v", v, err)
+		}
+		aclToken.ExpirationTTL = ttl
+	}
+
+	if err := aclClient.CreateToken(aclToken); err != nil {
+		return err
+	}
+
+	d.SetId(aclToken.AccessorID)
+
+	return resourceConsulACLTokenRead(d, meta)
+}
+
+func resourceConsulACLTokenUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	// The locality and the expiration of a token cannot be changed, but
+	// the locality must still be given.
+	aclToken := expandACLToken(d)
+	aclToken.AccessorID = d.Id()
+	aclToken.Local = d.Get("local").(bool)
+	if err := aclClient.UpdateToken(aclToken); err != nil {
+		return err
+	}
+
+	return resourceConsulACLTokenRead(d, meta)
+}
+
+func resourceConsulACLTokenRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+
+	aclToken, err := aclClient.ReadToken(d.Id())
+	if err != nil {
+		return err
+	}
+	if aclToken == nil {
+		log.Printf("[WARN] ACL token '%s' not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("accessor_id", aclToken.AccessorID)
+	d.Set("description", aclToken.Description)
+	d.Set("local", aclToken.Local)
+	if err := d.Set("policies", flattenACLLinks(aclToken.Policies)); err != nil {
+		return err
+	}
+	if err := d.Set("roles", flattenACLLinks(aclToken.Roles)); err != nil {
+		return err
+	}
+	if err := d.Set("service_identities", flattenACLServiceIdentities(aclToken.ServiceIdentities)); err != nil {
+		return err
+	}
+
+	d.Set("expiration_time", formatACLExpirationTime(aclToken.ExpirationTime))
+	if ttl := aclTokenExpirationTTL(aclToken); !suppressEquivalentDurations("expiration_ttl", d.Get("expiration_ttl").(string), ttl, d) {
+		d.Set("expiration_ttl", ttl)
+	}
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	return nil
+}
+
+func resourceConsulACLTokenDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	aclClient := newACLClient(acl, dc, token)
+	if err := aclClient.DeleteToken(d.Id()); err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// expandACLToken returns the token described by the attributes of d that
+// can be updated.
+func expandACLToken(d *schema.ResourceData) *consulapi.ACLToken {
+	return &consulapi.ACLToken{
+		Description:       d.Get("description").(string),
+		Policies:          expandACLLinks(d.Get("policies").(*schema.Set)),
+		Roles:             expandACLLinks(d.Get("roles").(*schema.Set)),
+		ServiceIdentities: expandACLServiceIdentities(d.Get("service_identities").([]interface{})),
+	}
+}
+
+// expandACLLinks returns links to the policies or roles whose names are in
+// names.
+func expandACLLinks(names *schema.Set) []*consulapi.ACLLink {
+	links := make([]*consulapi.ACLLink, 0, names.Len())
+	for _, name := range names.List() {
+		links = append(links, &consulapi.ACLLink{Name: name.(string)})
+	}
+	return links
+}
+
+func flattenACLLinks(links []*consulapi.ACLLink) []string {
+	names := make([]string, 0, len(links))
+	for _, link := range links {
+		names = append(names, link.Name)
+	}
+	return names
+}
+
+// formatACLExpirationTime returns the expiration time of a token in
+// RFC 3339 format, or "" for the tokens that do not expire.
+func formatACLExpirationTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// aclTokenExpirationTTL returns the TTL the token was created with, which is
+// the time between its creation and its expiration, or "" for the tokens
+// that do not expire.
+func aclTokenExpirationTTL(token *consulapi.ACLToken) string {
+	if token.ExpirationTime == nil {
+		return ""
+	}
+	return token.ExpirationTime.Sub(token.CreateTime).String()
+}
+
+func expandACLServiceIdentities(l []interface{}) []*consulapi.ACLServiceIdentity {
+	identities := make([]*consulapi.ACLServiceIdentity, 0, len(l))
+	for _, raw := range l {
+		m := raw.(map[string]interface{})
+		identity := &consulapi.ACLServiceIdentity{
+			ServiceName: m["service_name"].(string),
+		}
+		for _, dc := range m["datacenters"].(*schema.Set).List() {
+			identity.Datacenters = append(identity.Datacenters, dc.(string))
+		}
+		identities = append(identities, identity)
+	}
+	return identities
+}
+
+func flattenACLServiceIdentities(identities []*consulapi.ACLServiceIdentity) []interface{} {
+	l := make([]interface{}, 0, len(identities))
+	for _, identity := range identities {
+		datacenters := make([]interface{}, 0, len(identity.Datacenters))
+		for _, dc := range identity.Datacenters {
+			datacenters = append(datacenters, dc)
+		}
+		l = append(l, map[string]interface{}{
+			"service_name": identity.ServiceName,
+			"datacenters":  schema.NewSet(schema.HashString, datacenters),
+		})
+	}
+	return l
+}
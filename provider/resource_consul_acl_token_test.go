@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulACLToken_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulACLTokenDestroy(consul),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulACLTokenConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulACLTokenPolicies(consul, "consulclient_acl_token.app", "app"),
+					resource.TestCheckResourceAttrSet("consulclient_acl_token.app", "accessor_id"),
+					resource.TestCheckResourceAttr("consulclient_acl_token.app", "description", "Application token"),
+					resource.TestCheckResourceAttr("consulclient_acl_token.app", "policies.#", "1"),
+					resource.TestCheckResourceAttr("consulclient_acl_token.app", "roles.#", "1"),
+					resource.TestCheckResourceAttr("consulclient_acl_token.app", "service_identities.0.service_name", "web"),
+					resource.TestCheckResourceAttr("consulclient_acl_token.app", "local", "false"),
+					resource.TestCheckResourceAttr("consulclient_acl_token.app", "expiration_time", ""),
+					resource.TestCheckNoResourceAttr("consulclient_acl_token.app", "secret_id"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulACLTokenConfig_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulACLTokenPolicies(consul, "consulclient_acl_token.app", "app", "admin"),
+					resource.TestCheckResourceAttr("consulclient_acl_token.app", "policies.#", "2"),
+					resource.TestCheckResourceAttr("consulclient_acl_token.app", "roles.#", "0"),
+					resource.TestCheckResourceAttr("consulclient_acl_token.app", "service_identities.#", "0"),
+				),
+			},
+			{
+				ResourceName:      "consulclient_acl_token.app",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulACLToken_localExpiring(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulACLTokenDestroy(consul),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulACLTokenConfig_localExpiring,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulclient_acl_token.app", "accessor_id", "00000000-0000-0000-0000-00000000abcd"),
+					resource.TestCheckResourceAttr("consulclient_acl_token.app", "local", "true"),
+					resource.TestCheckResourceAttr("consulclient_acl_token.app", "expiration_ttl", "1h"),
+					resource.TestCheckResourceAttrSet("consulclient_acl_token.app", "expiration_time"),
+					resource.TestCheckResourceAttr("data.consulclient_acl_token_secret_id.app", "accessor_id", "00000000-0000-0000-0000-00000000abcd"),
+					testAccCheckConsulACLTokenSecretID(consul, "data.consulclient_acl_token_secret_id.app"),
+				),
+			},
+			{
+				// The TTL of an imported token is read from its creation
+				// and expiration times.
+				ResourceName: "consulclient_acl_token.app",
+				ImportState:  true,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected 1 state, got %d", len(states))
+					}
+					if ttl := states[0].Attributes["expiration_ttl"]; ttl != "1h0m0s" {
+						return fmt.Errorf("bad expiration_ttl: %q", ttl)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func testAccCheckConsulACLTokenPolicies(consul *fakeConsul, name string, policies ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		token, ok := consul.aclTokens[rs.Primary.ID]
+		if !ok {
+			return fmt.Errorf("ACL token '%s' does not exist", rs.Primary.ID)
+		}
+		if len(token.Policies) != len(policies) {
+			return fmt.Errorf("ACL token '%s' has %d policies; want %d", rs.Primary.ID, len(token.Policies), len(policies))
+		}
+		for _, name := range policies {
+			found := false
+			for _, link := range token.Policies {
+				found = found || link.Name == name
+			}
+			if !found {
+				return fmt.Errorf("ACL token '%s' is not linked to policy '%s'", rs.Primary.ID, name)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulACLTokenSecretID(consul *fakeConsul, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		token, ok := consul.aclTokens[rs.Primary.ID]
+		if !ok {
+			return fmt.Errorf("ACL token '%s' does not exist", rs.Primary.ID)
+		}
+		if secretID := rs.Primary.Attributes["secret_id"]; secretID != token.SecretID {
+			return fmt.Errorf("Read secret ID %q; want %q", secretID, token.SecretID)
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulACLTokenDestroy(consul *fakeConsul) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		if len(consul.aclTokens) != 0 {
+			return fmt.Errorf("%d ACL tokens still exist", len(consul.aclTokens))
+		}
+		return nil
+	}
+}
+
+const testAccConsulACLTokenConfig = `
+resource "consulclient_acl_policy" "app" {
+	name  = "app"
+	rules = "key_prefix \"app/\" { policy = \"read\" }"
+}
+
+resource "consulclient_acl_role" "ops" {
+	name = "ops"
+}
+
+resource "consulclient_acl_token" "app" {
+	description = "Application token"
+	policies    = ["${consulclient_acl_policy.app.name}"]
+	roles       = ["${consulclient_acl_role.ops.name}"]
+
+	service_identities {
+		service_name = "web"
+	}
+}
+`
+
+const testAccConsulACLTokenConfig_update = `
+resource "consulclient_acl_policy" "app" {
+	name  = "app"
+	rules = "key_prefix \"app/\" { policy = \"read\" }"
+}
+
+resource "consulclient_acl_policy" "admin" {
+	name  = "admin"
+	rules = "operator = \"write\""
+}
+
+resource "consulclient_acl_role" "ops" {
+	name = "ops"
+}
+
+resource "consulclient_acl_token" "app" {
+	description = "Application token"
+	policies    = ["${consulclient_acl_policy.app.name}", "${consulclient_acl_policy.admin.name}"]
+}
+`
+
+const testAccConsulACLTokenConfig_localExpiring = `
+resource "consulclient_acl_token" "app" {
+	accessor_id    = "00000000-0000-0000-0000-00000000abcd"
+	local          = true
+	expiration_ttl = "1h"
+}
+
+data "consulclient_acl_token_secret_id" "app" {
+	accessor_id = "${consulclient_acl_token.app.id}"
+}
+`
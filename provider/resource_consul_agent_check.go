@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceConsulAgentCheck() *schema.Resource {
+	s := healthCheckSchema()
+
+	s["host"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		ForceNew: true,
+	}
+
+	s["scheme"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		ForceNew: true,
+	}
+
+	s["http_auth"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+
+	s["ca_file"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+
+	s["cert_file"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+
+	s["key_file"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+
+	s["token"] = schemaToken
+	s["token_file"] = schemaTokenFile
+	s["token_command"] = schemaTokenCommand
+
+	// The ID of a check defaults to its name.
+	s["check_id"].ForceNew = true
+
+	s["service_id"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		ForceNew: true,
+	}
+
+	// The status a check is registered with. It is only used when the check
+	// is created: later registrations keep the status the check has reached.
+	s["status"].ValidateFunc = makeValidationFunc("status", []interface{}{
+		validateRegexp(`^(passing|warning|critical)$`),
+	})
+
+	s["current_status"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	s["output"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	return &schema.Resource{
+		Create: resourceConsulAgentCheckCreate,
+		Update: resourceConsulAgentCheckUpdate,
+		Read:   resourceConsulAgentCheckRead,
+		Delete: resourceConsulAgentCheckDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulImportState,
+		},
+
+		Schema: s,
+	}
+}
+
+func resourceConsulAgentCheckCreate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceConsulAgentCheckRegister(d, meta, true); err != nil {
+		return err
+	}
+
+	return resourceConsulAgentCheckRead(d, meta)
+}
+
+func resourceConsulAgentCheckUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceConsulAgentCheckRegister(d, meta, false); err != nil {
+		return err
+	}
+
+	return resourceConsulAgentCheckRead(d, meta)
+}
+
+// resourceConsulAgentCheckRegister registers the check with the agent,
+// replacing any previous definition. The configured status is only used
+// when the check is created.
+func resourceConsulAgentCheckRegister(d *schema.ResourceData, meta interface{}, create bool) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	agent := client.Agent()
+
+	m := make(map[string]interface{})
+	for k := range healthCheckSchema() {
+		m[k] = d.Get(k)
+	}
+	check, err := expandHealthCheck(m)
+	if err != nil {
+		return err
+	}
+
+	id := check.CheckID
+	if id == "" {
+		id = check.Name
+	}
+	check.CheckID = ""
+	if !create {
+		check.Status = d.Get("current_status").(string)
+	}
+
+	registration := &consulapi.AgentCheckRegistration{
+		ID:                id,
+		Name:              check.Name,
+		Notes:             check.Notes,
+		ServiceID:         d.Get("service_id").(string),
+		AgentServiceCheck: *check,
+	}
+
+	if err := agent.CheckRegister(registration); err != nil {
+		return fmt.Errorf("Failed to register check '%s' with Consul agent: %v", id, err)
+	}
+	d.SetId(id)
+
+	return nil
+}
+
+func resourceConsulAgentCheckRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	agent := client.Agent()
+
+	id := d.Id()
+
+	checks, err := agent.Checks()
+	if err != nil {
+		return fmt.Errorf("Failed to get checks from Consul agent: %v", err)
+	}
+
+	check, ok := checks[id]
+	if !ok {
+		log.Printf("[WARN] Check '%s' not registered with Consul agent, removing from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	for k, v := range flattenHealthCheck(check, map[string]interface{}{}) {
+		if err := d.Set(k, v); err != nil {
+			return fmt.Errorf("Failed to store %s of check '%s': %v", k, id, err)
+		}
+	}
+	d.Set("service_id", check.ServiceID)
+	d.Set("current_status", check.Status)
+	d.Set("output", check.Output)
+
+	return nil
+}
+
+func resourceConsulAgentCheckDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	agent := client.Agent()
+
+	id := d.Id()
+
+	if err := agent.CheckDeregister(id); err != nil {
+		return fmt.Errorf("Failed to deregister check '%s' from Consul agent: %v", id, err)
+	}
+
+	// Clear the ID
+	d.SetId("")
+	return nil
+}
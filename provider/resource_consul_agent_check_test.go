@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulAgentCheck_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulAgentCheckDestroy(consul, "disk", "web-http"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulAgentCheckConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulAgentCheckExists(consul, "disk", "", "ttl"),
+					testAccCheckConsulAgentCheckStatus(consul, "disk", "passing"),
+					testAccCheckConsulAgentCheckExists(consul, "web-http", "web", "http"),
+					resource.TestCheckResourceAttr("consulclient_agent_check.disk", "id", "disk"),
+					resource.TestCheckResourceAttr("consulclient_agent_check.disk", "check_id", "disk"),
+					resource.TestCheckResourceAttr("consulclient_agent_check.disk", "current_status", "passing"),
+					resource.TestCheckResourceAttr("consulclient_agent_check.web", "id", "web-http"),
+					resource.TestCheckResourceAttr("consulclient_agent_check.web", "service_id", "web"),
+					resource.TestCheckResourceAttr("consulclient_agent_check.web", "current_status", "critical"),
+					resource.TestCheckResourceAttr("consulclient_agent_check.web", "interval", "10s"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulAgentCheckConfig_update,
+				Check: resource.ComposeTestCheckFunc(
+					// The status is only set when the check is created.
+					testAccCheckConsulAgentCheckStatus(consul, "disk", "passing"),
+					resource.TestCheckResourceAttr("consulclient_agent_check.disk", "current_status", "passing"),
+					resource.TestCheckResourceAttr("consulclient_agent_check.web", "interval", "30s"),
+					resource.TestCheckResourceAttr("consulclient_agent_check.web", "method", "HEAD"),
+				),
+			},
+			{
+				// A check deregistered out of band is removed from state.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					delete(consul.checks, "web-http")
+				},
+				Config:             testAccProviderConfig(consul) + testAccConsulAgentCheckConfig_update,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulAgentCheckConfig_update,
+				Check:  testAccCheckConsulAgentCheckExists(consul, "web-http", "web", "http"),
+			},
+			{
+				ResourceName:            "consulclient_agent_check.web",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"check_id"},
+			},
+		},
+	})
+}
+
+func TestAccConsulAgentCheck_invalid(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderConfig(consul) + testAccConsulAgentCheckConfig_invalid,
+				ExpectError: regexp.MustCompile("must set exactly one of"),
+			},
+		},
+	})
+}
+
+func testAccCheckConsulAgentCheckStatus(consul *fakeConsul, id, status string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		check, ok := consul.checks[id]
+		if !ok {
+			return fmt.Errorf("Check '%s' is not registered with the agent", id)
+		}
+		if check.Status != status {
+			return fmt.Errorf("Check '%s' has status '%s'; want '%s'", id, check.Status, status)
+		}
+		return nil
+	}
+}
+
+const testAccConsulAgentCheckConfig = `
+resource "consulclient_agent_service" "web" {
+	name = "web"
+	port = 80
+}
+
+resource "consulclient_agent_check" "disk" {
+	name       = "disk"
+	notes      = "Disk usage"
+	ttl        = "30s"
+	status     = "passing"
+}
+
+resource "consulclient_agent_check" "web" {
+	check_id   = "web-http"
+	name       = "Web HTTP"
+	service_id = "${consulclient_agent_service.web.id}"
+	http       = "http://localhost:80/health"
+	interval   = "10s"
+}
+`
+
+const testAccConsulAgentCheckConfig_update = `
+resource "consulclient_agent_service" "web" {
+	name = "web"
+	port = 80
+}
+
+resource "consulclient_agent_check" "disk" {
+	name       = "disk"
+	notes      = "Disk usage"
+	ttl        = "30s"
+	status     = "warning"
+}
+
+resource "consulclient_agent_check" "web" {
+	check_id   = "web-http"
+	name       = "Web HTTP"
+	service_id = "${consulclient_agent_service.web.id}"
+	http       = "http://localhost:80/health"
+	method     = "HEAD"
+	interval   = "30s"
+}
+`
+
+const testAccConsulAgentCheckConfig_invalid = `
+resource "consulclient_agent_check" "invalid" {
+	name     = "invalid"
+	http     = "http://localhost:80/health"
+	tcp      = "localhost:80"
+	interval = "10s"
+}
+`
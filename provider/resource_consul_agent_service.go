@@ -17,6 +17,10 @@ func resourceConsulAgentService() *schema.Resource {
 		Read:   resourceConsulAgentServiceRead,
 		Delete: resourceConsulAgentServiceDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulImportState,
+		},
+
 		Schema: map[string]*schema.Schema{
 			"host": {
 				Type:     schema.TypeString,
@@ -48,10 +52,9 @@ func resourceConsulAgentService() *schema.Resource {
 				Optional: true,
 			},
 
-			"token": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
 
 			"address": {
 				Type:     schema.TypeString,
@@ -82,6 +85,25 @@ func resourceConsulAgentService() *schema.Resource {
 				Elem:     &schema.Schema{Type: schema.TypeString},
 				ForceNew: true,
 			},
+
+			"meta": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"enable_tag_override": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+
+			"check": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: healthCheckSchema(),
+				},
+			},
 		},
 	}
 }
@@ -118,10 +140,23 @@ func resourceConsulAgentServiceCreate(d *schema.ResourceData, meta interface{})
 		registration.Tags = s
 	}
 
+	registration.Meta = expandStringMap(d.Get("meta"))
+	registration.EnableTagOverride = d.Get("enable_tag_override").(bool)
+
+	checks, err := expandServiceChecks(d, name)
+	if err != nil {
+		return err
+	}
+	registration.Checks = checks
+
 	if err := agent.ServiceRegister(&registration); err != nil {
 		return fmt.Errorf("Failed to register service '%s' with Consul agent: %v", name, err)
 	}
 
+	if err := deregisterRemovedServiceChecks(agent, d, name, checks); err != nil {
+		return err
+	}
+
 	// Update the resource
 	if serviceMap, err := agent.Services(); err != nil {
 		return fmt.Errorf("Failed to read services from Consul agent: %v", err)
@@ -138,6 +173,18 @@ func resourceConsulAgentServiceCreate(d *schema.ResourceData, meta interface{})
 			tags = append(tags, tag)
 		}
 		d.Set("tags", tags)
+		if err := d.Set("meta", service.Meta); err != nil {
+			return fmt.Errorf("Failed to store meta of service '%s': %v", service.ID, err)
+		}
+		d.Set("enable_tag_override", service.EnableTagOverride)
+
+		checks, err := readServiceChecks(agent, d, service.ID)
+		if err != nil {
+			return err
+		}
+		if err := d.Set("check", checks); err != nil {
+			return fmt.Errorf("Failed to store checks of service '%s': %v", service.ID, err)
+		}
 	}
 
 	return nil
@@ -155,12 +202,13 @@ func resourceConsulAgentServiceRead(d *schema.ResourceData, meta interface{}) er
 	}
 	agent := client.Agent()
 
-	name := d.Get("name").(string)
+	id := d.Id()
 
 	if services, err := agent.Services(); err != nil {
 		return fmt.Errorf("Failed to get services from Consul agent: %v", err)
-	} else if service, ok := services[name]; !ok {
+	} else if service, ok := services[id]; !ok {
 		d.Set("id", "")
+		d.SetId("")
 	} else {
 		d.Set("address", service.Address)
 		d.Set("id", service.ID)
@@ -172,6 +220,18 @@ func resourceConsulAgentServiceRead(d *schema.ResourceData, meta interface{}) er
 			tags = append(tags, tag)
 		}
 		d.Set("tags", tags)
+		if err := d.Set("meta", service.Meta); err != nil {
+			return fmt.Errorf("Failed to store meta of service '%s': %v", service.ID, err)
+		}
+		d.Set("enable_tag_override", service.EnableTagOverride)
+
+		checks, err := readServiceChecks(agent, d, service.ID)
+		if err != nil {
+			return err
+		}
+		if err := d.Set("check", checks); err != nil {
+			return fmt.Errorf("Failed to store checks of service '%s': %v", service.ID, err)
+		}
 	}
 
 	return nil
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccConsulAgentService_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulAgentServiceDestroy(consul, "google"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulAgentServiceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulAgentServiceExists(consul, "google", "google"),
+					resource.TestCheckResourceAttr("consulclient_agent_service.app", "address", "www.google.com"),
+					resource.TestCheckResourceAttr("consulclient_agent_service.app", "id", "google"),
+					resource.TestCheckResourceAttr("consulclient_agent_service.app", "name", "google"),
+					resource.TestCheckResourceAttr("consulclient_agent_service.app", "port", "80"),
+					resource.TestCheckResourceAttr("consulclient_agent_service.app", "tags.#", "2"),
+				),
+			},
+			{
+				ResourceName:      "consulclient_agent_service.app",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulAgentService_checks(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulAgentCheckDestroy(consul, "service:google"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulAgentServiceConfig_checks,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulAgentCheckExists(consul, "service:google", "google", "http"),
+					resource.TestCheckResourceAttr("consulclient_agent_service.app", "check.#", "1"),
+					resource.TestCheckResourceAttr("consulclient_agent_service.app", "check.0.check_id", "service:google"),
+					resource.TestCheckResourceAttr("consulclient_agent_service.app", "check.0.tls_skip_verify", "true"),
+				),
+			},
+			{
+				// A check deregistered out of band is detected.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					delete(consul.checks, "service:google")
+				},
+				Config:             testAccProviderConfig(consul) + testAccConsulAgentServiceConfig_checks,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulAgentServiceConfig_checks,
+				Check:  testAccCheckConsulAgentCheckExists(consul, "service:google", "google", "http"),
+			},
+		},
+	})
+}
+
+func TestAccConsulAgentService_multipleEndpoints(t *testing.T) {
+	primary := newFakeConsul("dc1")
+	defer primary.Close()
+	secondary := newFakeConsul("dc2")
+	defer secondary.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckConsulAgentServiceDestroy(primary, "google"),
+			testAccCheckConsulAgentServiceDestroy(secondary, "google"),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(primary) + fmt.Sprintf(testAccConsulAgentServiceConfig_host, secondary.Address()),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulAgentServiceDestroy(primary, "google"),
+					testAccCheckConsulAgentServiceExists(secondary, "google", "google"),
+				),
+			},
+		},
+	})
+}
+
+const testAccConsulAgentServiceConfig = `
+resource "consulclient_agent_service" "app" {
+	address = "www.google.com"
+	name    = "google"
+	port    = 80
+	tags    = ["tag0", "tag1"]
+}
+`
+
+const testAccConsulAgentServiceConfig_host = `
+resource "consulclient_agent_service" "app" {
+	host    = "%s"
+	address = "www.google.com"
+	name    = "google"
+	port    = 80
+}
+`
+
+const testAccConsulAgentServiceConfig_checks = `
+resource "consulclient_agent_service" "app" {
+	address = "www.google.com"
+	name    = "google"
+	port    = 443
+
+	check {
+		name            = "HTTPS"
+		http            = "https://www.google.com"
+		tls_skip_verify = true
+		interval        = "10s"
+		timeout         = "2s"
+	}
+}
+`
@@ -6,8 +6,8 @@ package provider
 import (
 	"bytes"
 	"fmt"
+	"log"
 	"sort"
-	"strings"
 
 	consulapi "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/terraform/helper/hashcode"
@@ -17,10 +17,14 @@ import (
 func resourceConsulCatalogEntry() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceConsulCatalogEntryCreate,
-		Update: resourceConsulCatalogEntryCreate,
+		Update: resourceConsulCatalogEntryUpdate,
 		Read:   resourceConsulCatalogEntryRead,
 		Delete: resourceConsulCatalogEntryDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulCatalogEntryImport,
+		},
+
 		Schema: map[string]*schema.Schema{
 			"host": {
 				Type:     schema.TypeString,
@@ -71,53 +75,82 @@ func resourceConsulCatalogEntry() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"node_meta": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"tagged_addresses": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
 			"service": {
 				Type:     schema.TypeSet,
 				Optional: true,
-				ForceNew: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"address": {
 							Type:     schema.TypeString,
 							Optional: true,
-							ForceNew: true,
 						},
 
 						"id": {
 							Type:     schema.TypeString,
 							Optional: true,
 							Computed: true,
-							ForceNew: true,
 						},
 
 						"name": {
 							Type:     schema.TypeString,
 							Required: true,
-							ForceNew: true,
 						},
 
 						"port": {
 							Type:     schema.TypeInt,
 							Optional: true,
-							ForceNew: true,
 						},
 
 						"tags": {
 							Type:     schema.TypeSet,
 							Optional: true,
-							ForceNew: true,
 							Elem:     &schema.Schema{Type: schema.TypeString},
 							Set:      resourceConsulCatalogEntryServiceTagsHash,
 						},
+
+						// The meta of a service is computed so that the diff
+						// of a replaced service does not carry a count for its
+						// meta, which would read back as an extra service
+						// holding just the old ID.
+						"meta": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						"enable_tag_override": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
 					},
 				},
 				Set: resourceConsulCatalogEntryServicesHash,
 			},
 
-			"token": {
-				Type:     schema.TypeString,
+			"check": {
+				Type:     schema.TypeList,
 				Optional: true,
+				Elem: &schema.Resource{
+					Schema: catalogHealthCheckSchema(),
+				},
 			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
 		},
 	}
 }
@@ -145,10 +178,37 @@ func resourceConsulCatalogEntryServicesHash(v interface{}) int {
 			buf.WriteString(fmt.Sprintf("%s-", v))
 		}
 	}
+	if v, ok := m["meta"]; ok {
+		meta := v.(map[string]interface{})
+		keys := make([]string, 0, len(meta))
+		for k := range meta {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			buf.WriteString(fmt.Sprintf("%s=%s-", k, meta[k].(string)))
+		}
+	}
+	if v, ok := m["enable_tag_override"]; ok {
+		buf.WriteString(fmt.Sprintf("%t-", v.(bool)))
+	}
 	return hashcode.String(buf.String())
 }
 
 func resourceConsulCatalogEntryCreate(d *schema.ResourceData, meta interface{}) error {
+	return resourceConsulCatalogEntryRegister(d, meta, true)
+}
+
+func resourceConsulCatalogEntryUpdate(d *schema.ResourceData, meta interface{}) error {
+	return resourceConsulCatalogEntryRegister(d, meta, false)
+}
+
+// resourceConsulCatalogEntryRegister registers the node along with its
+// services and checks. Unless the resource is being created, only the
+// services that changed are registered again, and the services and checks
+// removed from the configuration are deregistered.
+func resourceConsulCatalogEntryRegister(d *schema.ResourceData, meta interface{}, create bool) error {
 	config := meta.(*ProviderConfig)
 	resolvedConfig, _, err := config.GetResolvedConfig(d)
 	if err != nil {
@@ -165,15 +225,12 @@ func resourceConsulCatalogEntryCreate(d *schema.ResourceData, meta interface{})
 		dc = v.(string)
 	} else {
 		var err error
-		if dc, err = getDC(d, client); err != nil {
+		if dc, err = resolvedConfig.getDC(d, client); err != nil {
 			return err
 		}
 	}
 
-	var token string
-	if v, ok := d.GetOk("token"); ok {
-		token = v.(string)
-	}
+	token := resolvedConfig.Token
 
 	// Setup the operations using the datacenter
 	wOpts := consulapi.WriteOptions{Datacenter: dc, Token: token}
@@ -181,73 +238,114 @@ func resourceConsulCatalogEntryCreate(d *schema.ResourceData, meta interface{})
 	address := d.Get("address").(string)
 	node := d.Get("node").(string)
 
-	var serviceIDs []string
-	if service, ok := d.GetOk("service"); ok {
-		serviceList := service.(*schema.Set).List()
-		serviceIDs = make([]string, len(serviceList))
-		for i, rawService := range serviceList {
-			serviceData := rawService.(map[string]interface{})
+	o, n := d.GetChange("service")
+	oldServices, newServices := o.(*schema.Set), n.(*schema.Set)
 
-			if len(serviceData["id"].(string)) == 0 {
-				serviceData["id"] = serviceData["name"].(string)
-			}
-			serviceID := serviceData["id"].(string)
-			serviceIDs[i] = serviceID
-
-			var tags []string
-			if v := serviceData["tags"].(*schema.Set).List(); len(v) > 0 {
-				tags = make([]string, len(v))
-				for i, raw := range v {
-					tags[i] = raw.(string)
-				}
-			}
+	register := newServices
+	if !create {
+		register = newServices.Difference(oldServices)
+	}
 
-			registration := &consulapi.CatalogRegistration{
-				Address:    address,
-				Datacenter: dc,
-				Node:       node,
-				Service: &consulapi.AgentService{
-					Address: serviceData["address"].(string),
-					ID:      serviceID,
-					Service: serviceData["name"].(string),
-					Port:    serviceData["port"].(int),
-					Tags:    tags,
-				},
-			}
+	serviceIDs := make(map[string]bool, newServices.Len())
+	for _, raw := range newServices.List() {
+		serviceIDs[catalogEntryServiceID(raw.(map[string]interface{}))] = true
+	}
 
-			if _, err := catalog.Register(registration, &wOpts); err != nil {
-				return fmt.Errorf("Failed to register Consul catalog entry with node '%s' at address '%s' in %s: %v",
-					node, address, dc, err)
-			}
-		}
-	} else {
-		registration := &consulapi.CatalogRegistration{
-			Address:    address,
-			Datacenter: dc,
-			Node:       node,
+	for _, raw := range register.List() {
+		serviceData := raw.(map[string]interface{})
+		registration := catalogEntryRegistration(d, dc)
+		registration.Service = catalogEntryService(serviceData)
+		if _, err := catalog.Register(registration, &wOpts); err != nil {
+			return fmt.Errorf("Failed to register service '%s' of Consul catalog entry with node '%s' at address '%s' in %s: %v",
+				registration.Service.ID, node, address, dc, err)
 		}
+	}
+
+	// Checks are registered last, as they may be bound to any of the
+	// services of the node. The node itself is registered along with them
+	// so that it exists even without services.
+	checks := expandCatalogHealthChecks(d, node)
+	if create || d.HasChange("check") || d.HasChange("node_meta") || d.HasChange("tagged_addresses") {
+		registration := catalogEntryRegistration(d, dc)
+		registration.Checks = checks
 
 		if _, err := catalog.Register(registration, &wOpts); err != nil {
 			return fmt.Errorf("Failed to register Consul catalog entry with node '%s' at address '%s' in %s: %v",
 				node, address, dc, err)
 		}
+
+		if err := deregisterRemovedCatalogHealthChecks(catalog, d, node, checks, &wOpts); err != nil {
+			return err
+		}
 	}
 
-	// Update the resource
-	qOpts := consulapi.QueryOptions{Datacenter: dc}
-	if _, _, err := catalog.Node(node, &qOpts); err != nil {
-		return fmt.Errorf("Failed to read Consul catalog entry for node '%s' at address '%s' in %s: %v",
-			node, address, dc, err)
-	} else {
-		d.Set("datacenter", dc)
+	// Services that were changed in place keep their ID and have just been
+	// registered again, so only the ones that are gone are deregistered.
+	for _, raw := range oldServices.Difference(newServices).List() {
+		serviceID := catalogEntryServiceID(raw.(map[string]interface{}))
+		if serviceIDs[serviceID] {
+			continue
+		}
+
+		deregistration := &consulapi.CatalogDeregistration{
+			Datacenter: dc,
+			Node:       node,
+			ServiceID:  serviceID,
+		}
+		if _, err := catalog.Deregister(deregistration, &wOpts); err != nil {
+			return fmt.Errorf("Failed to deregister service '%s' of Consul catalog entry with node '%s' in %s: %v",
+				serviceID, node, dc, err)
+		}
 	}
 
-	sort.Strings(serviceIDs)
-	serviceIDsJoined := strings.Join(serviceIDs, ",")
+	d.Set("datacenter", dc)
+	d.SetId(node)
 
-	d.SetId(fmt.Sprintf("%s-%s-[%s]", node, address, serviceIDsJoined))
+	return resourceConsulCatalogEntryRead(d, meta)
+}
 
-	return nil
+// catalogEntryServiceID returns the ID of a service of a catalog entry,
+// which defaults to its name.
+func catalogEntryServiceID(serviceData map[string]interface{}) string {
+	if id := serviceData["id"].(string); id != "" {
+		return id
+	}
+	return serviceData["name"].(string)
+}
+
+// catalogEntryRegistration returns the registration of the node of a
+// catalog entry. Every registration updates the node, so they all carry its
+// metadata and tagged addresses.
+func catalogEntryRegistration(d *schema.ResourceData, dc string) *consulapi.CatalogRegistration {
+	return &consulapi.CatalogRegistration{
+		Address:         d.Get("address").(string),
+		Datacenter:      dc,
+		Node:            d.Get("node").(string),
+		NodeMeta:        expandStringMap(d.Get("node_meta")),
+		TaggedAddresses: expandStringMap(d.Get("tagged_addresses")),
+	}
+}
+
+// catalogEntryService returns the definition of a service of a catalog
+// entry.
+func catalogEntryService(serviceData map[string]interface{}) *consulapi.AgentService {
+	var tags []string
+	if v := serviceData["tags"].(*schema.Set).List(); len(v) > 0 {
+		tags = make([]string, len(v))
+		for i, raw := range v {
+			tags[i] = raw.(string)
+		}
+	}
+
+	return &consulapi.AgentService{
+		Address:           serviceData["address"].(string),
+		ID:                catalogEntryServiceID(serviceData),
+		Service:           serviceData["name"].(string),
+		Port:              serviceData["port"].(int),
+		Tags:              tags,
+		Meta:              expandStringMap(serviceData["meta"]),
+		EnableTagOverride: serviceData["enable_tag_override"].(bool),
+	}
 }
 
 func resourceConsulCatalogEntryRead(d *schema.ResourceData, meta interface{}) error {
@@ -262,10 +360,9 @@ func resourceConsulCatalogEntryRead(d *schema.ResourceData, meta interface{}) er
 	}
 	catalog := client.Catalog()
 
-	// Get the DC, error if not available.
-	var dc string
-	if v, ok := d.GetOk("datacenter"); ok {
-		dc = v.(string)
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
 	}
 
 	node := d.Get("node").(string)
@@ -273,10 +370,65 @@ func resourceConsulCatalogEntryRead(d *schema.ResourceData, meta interface{}) er
 	// Setup the operations using the datacenter
 	qOpts := consulapi.QueryOptions{Datacenter: dc}
 
-	if _, _, err := catalog.Node(node, &qOpts); err != nil {
+	catalogNode, _, err := catalog.Node(node, &qOpts)
+	if err != nil {
 		return fmt.Errorf("Failed to get node '%s' from Consul catalog: %v", node, err)
 	}
 
+	if catalogNode == nil || catalogNode.Node == nil {
+		log.Printf("[WARN] Consul catalog node '%s' not found in %s, removing from state", node, dc)
+		d.SetId("")
+		return nil
+	}
+
+	// Only refresh the services managed by this resource: other services may
+	// be registered on the same node by agents or other resources. Services
+	// that are gone are dropped so that they get registered again.
+	var services []interface{}
+	if v, ok := d.GetOk("service"); ok {
+		for _, raw := range v.(*schema.Set).List() {
+			serviceData := raw.(map[string]interface{})
+
+			serviceID := serviceData["id"].(string)
+			if serviceID == "" {
+				serviceID = serviceData["name"].(string)
+			}
+
+			service, ok := catalogNode.Services[serviceID]
+			if !ok {
+				log.Printf("[WARN] Service '%s' not registered on Consul catalog node '%s', removing from state", serviceID, node)
+				continue
+			}
+
+			// Keep the ID as it was given, since it defaults to the name
+			// of the service when left empty.
+			m := flattenCatalogEntryService(service)
+			m["id"] = serviceData["id"]
+			services = append(services, m)
+		}
+	}
+
+	checks, err := readCatalogHealthChecks(client.Health(), d, node, &qOpts)
+	if err != nil {
+		return err
+	}
+
+	d.Set("address", catalogNode.Node.Address)
+	d.Set("node", catalogNode.Node.Node)
+	d.Set("datacenter", dc)
+	if err := d.Set("node_meta", catalogNode.Node.Meta); err != nil {
+		return fmt.Errorf("Failed to store node_meta of Consul catalog node '%s': %v", node, err)
+	}
+	if err := d.Set("tagged_addresses", catalogNode.Node.TaggedAddresses); err != nil {
+		return fmt.Errorf("Failed to store tagged_addresses of Consul catalog node '%s': %v", node, err)
+	}
+	if err := d.Set("service", services); err != nil {
+		return fmt.Errorf("Failed to store services of Consul catalog node '%s': %v", node, err)
+	}
+	if err := d.Set("check", checks); err != nil {
+		return fmt.Errorf("Failed to store checks of Consul catalog node '%s': %v", node, err)
+	}
+
 	return nil
 }
 
@@ -297,15 +449,12 @@ func resourceConsulCatalogEntryDelete(d *schema.ResourceData, meta interface{})
 		dc = v.(string)
 	} else {
 		var err error
-		if dc, err = getDC(d, client); err != nil {
+		if dc, err = resolvedConfig.getDC(d, client); err != nil {
 			return err
 		}
 	}
 
-	var token string
-	if v, ok := d.GetOk("token"); ok {
-		token = v.(string)
-	}
+	token := resolvedConfig.Token
 
 	// Setup the operations using the datacenter
 	wOpts := consulapi.WriteOptions{Datacenter: dc, Token: token}
@@ -313,18 +462,140 @@ func resourceConsulCatalogEntryDelete(d *schema.ResourceData, meta interface{})
 	address := d.Get("address").(string)
 	node := d.Get("node").(string)
 
-	deregistration := consulapi.CatalogDeregistration{
-		Address:    address,
-		Datacenter: dc,
-		Node:       node,
+	// Only deregister the services and checks managed by this resource,
+	// and the node itself once nothing else is registered on it.
+	for _, raw := range d.Get("service").(*schema.Set).List() {
+		serviceID := catalogEntryServiceID(raw.(map[string]interface{}))
+
+		deregistration := &consulapi.CatalogDeregistration{
+			Datacenter: dc,
+			Node:       node,
+			ServiceID:  serviceID,
+		}
+		if _, err := catalog.Deregister(deregistration, &wOpts); err != nil {
+			return fmt.Errorf("Failed to deregister service '%s' of Consul catalog entry with node '%s' in %s: %v",
+				serviceID, node, dc, err)
+		}
+	}
+
+	for _, check := range expandCatalogHealthChecks(d, node) {
+		deregistration := &consulapi.CatalogDeregistration{
+			Datacenter: dc,
+			Node:       node,
+			CheckID:    check.CheckID,
+		}
+		if _, err := catalog.Deregister(deregistration, &wOpts); err != nil {
+			return fmt.Errorf("Failed to deregister check '%s' of Consul catalog entry with node '%s' in %s: %v",
+				check.CheckID, node, dc, err)
+		}
 	}
 
-	if _, err := catalog.Deregister(&deregistration, &wOpts); err != nil {
-		return fmt.Errorf("Failed to deregister Consul catalog entry with node '%s' at address '%s' in %s: %v",
-			node, address, dc, err)
+	qOpts := consulapi.QueryOptions{Datacenter: dc}
+	catalogNode, _, err := catalog.Node(node, &qOpts)
+	if err != nil {
+		return fmt.Errorf("Failed to get node '%s' from Consul catalog: %v", node, err)
+	}
+	nodeChecks, _, err := client.Health().Node(node, &qOpts)
+	if err != nil {
+		return fmt.Errorf("Failed to get checks of Consul catalog node '%s': %v", node, err)
+	}
+
+	if catalogNode != nil && len(catalogNode.Services) == 0 && len(nodeChecks) == 0 {
+		deregistration := consulapi.CatalogDeregistration{
+			Address:    address,
+			Datacenter: dc,
+			Node:       node,
+		}
+
+		if _, err := catalog.Deregister(&deregistration, &wOpts); err != nil {
+			return fmt.Errorf("Failed to deregister Consul catalog entry with node '%s' at address '%s' in %s: %v",
+				node, address, dc, err)
+		}
 	}
 
 	// Clear the ID
 	d.SetId("")
 	return nil
 }
+
+// resourceConsulCatalogEntryImport imports the node whose name is given as
+// ID, along with all the services registered on it.
+func resourceConsulCatalogEntryImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := parseImportTarget(d); err != nil {
+		return nil, err
+	}
+
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return nil, err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	catalog := client.Catalog()
+
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return nil, err
+	}
+
+	node := d.Id()
+
+	// Setup the operations using the datacenter
+	qOpts := consulapi.QueryOptions{Datacenter: dc}
+
+	catalogNode, _, err := catalog.Node(node, &qOpts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get node '%s' from Consul catalog: %v", node, err)
+	}
+	if catalogNode == nil || catalogNode.Node == nil {
+		return nil, fmt.Errorf("Consul catalog node '%s' does not exist", node)
+	}
+
+	services := make([]interface{}, 0, len(catalogNode.Services))
+	for _, service := range catalogNode.Services {
+		services = append(services, flattenCatalogEntryService(service))
+	}
+
+	d.Set("node", catalogNode.Node.Node)
+	d.Set("address", catalogNode.Node.Address)
+	d.Set("datacenter", dc)
+	d.Set("node_meta", catalogNode.Node.Meta)
+	d.Set("tagged_addresses", catalogNode.Node.TaggedAddresses)
+	if err := d.Set("service", services); err != nil {
+		return nil, err
+	}
+	if err := importCatalogHealthChecks(client.Health(), d, node, &qOpts); err != nil {
+		return nil, err
+	}
+
+	d.SetId(catalogNode.Node.Node)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// flattenCatalogEntryService converts a service registered in the catalog
+// into the representation of the service attribute.
+func flattenCatalogEntryService(service *consulapi.AgentService) map[string]interface{} {
+	tags := make([]interface{}, 0, len(service.Tags))
+	for _, tag := range service.Tags {
+		tags = append(tags, tag)
+	}
+
+	meta := make(map[string]interface{}, len(service.Meta))
+	for k, v := range service.Meta {
+		meta[k] = v
+	}
+
+	return map[string]interface{}{
+		"address":             service.Address,
+		"id":                  service.ID,
+		"name":                service.Service,
+		"port":                service.Port,
+		"tags":                schema.NewSet(resourceConsulCatalogEntryServiceTagsHash, tags),
+		"meta":                meta,
+		"enable_tag_override": service.EnableTagOverride,
+	}
+}
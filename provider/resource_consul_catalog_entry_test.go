@@ -0,0 +1,545 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulCatalogEntry_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulNodeDestroy(consul, "foobar"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulNodeExists(consul, "foobar", "192.168.10.10"),
+					testAccCheckConsulCatalogEntryService(consul, "foobar", "redis1", 8000),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "address", "192.168.10.10"),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "node", "foobar"),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "service.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "consulclient_catalog_entry.app",
+				ImportState:       true,
+				ImportStateId:     "foobar",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulCatalogEntry_drift(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulNodeDestroy(consul, "foobar"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_drift,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulCatalogEntryService(consul, "foobar", "redis", 8000),
+					testAccCheckConsulCatalogEntryService(consul, "foobar", "web1", 80),
+				),
+			},
+			{
+				// A service changed out of band is detected.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.nodes["foobar"].services["redis"].Port = 9000
+				},
+				Config:             testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_drift,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_drift,
+				Check:  testAccCheckConsulCatalogEntryService(consul, "foobar", "redis", 8000),
+			},
+			{
+				// A service deregistered out of band is detected.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					delete(consul.nodes["foobar"].services, "web1")
+				},
+				Config:             testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_drift,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_drift,
+				Check:  testAccCheckConsulCatalogEntryService(consul, "foobar", "web1", 80),
+			},
+			{
+				// Services registered by others on the same node are ignored.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.nodes["foobar"].services["other"] = &consulapi.AgentService{ID: "other", Service: "other"}
+				},
+				Config:   testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_drift,
+				PlanOnly: true,
+			},
+			{
+				// A node deregistered out of band is removed from state.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					delete(consul.nodes, "foobar")
+				},
+				Config:             testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_drift,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_drift,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulCatalogEntryService(consul, "foobar", "redis", 8000),
+					testAccCheckConsulCatalogEntryService(consul, "foobar", "web1", 80),
+				),
+			},
+		},
+	})
+}
+
+func TestAccConsulCatalogEntry_meta(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulNodeDestroy(consul, "foobar"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_meta,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulCatalogEntryServiceMeta(consul, "foobar", "redis1", "version", "5"),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "node_meta.%", "1"),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "node_meta.rack", "r1"),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "tagged_addresses.lan", "192.168.10.10"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_metaUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulCatalogEntryServiceMeta(consul, "foobar", "redis1", "version", "6"),
+					func(s *terraform.State) error {
+						consul.lock.Lock()
+						defer consul.lock.Unlock()
+						n := consul.nodes["foobar"]
+						if n.node.Meta["rack"] != "r2" {
+							return fmt.Errorf("Node 'foobar' has meta %v; want rack=r2", n.node.Meta)
+						}
+						if !n.services["redis1"].EnableTagOverride {
+							return fmt.Errorf("Service 'redis1' does not enable tag override")
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:      "consulclient_catalog_entry.app",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_metaRemove,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "service.#", "1"),
+					func(s *terraform.State) error {
+						consul.lock.Lock()
+						defer consul.lock.Unlock()
+						n := consul.nodes["foobar"]
+						if len(n.services) != 1 {
+							return fmt.Errorf("Node 'foobar' has services %v; want only redis1", n.services)
+						}
+						if service := n.services["redis1"]; len(service.Meta) != 0 {
+							return fmt.Errorf("Service 'redis1' has meta %v; want none", service.Meta)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckConsulCatalogEntryServiceMeta(consul *fakeConsul, node, id, key, value string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		n, ok := consul.nodes[node]
+		if !ok {
+			return fmt.Errorf("Node '%s' does not exist", node)
+		}
+		service, ok := n.services[id]
+		if !ok {
+			return fmt.Errorf("Service '%s' is not registered on node '%s'", id, node)
+		}
+		if service.Meta[key] != value {
+			return fmt.Errorf("Service '%s' has meta %v; want %s=%s", id, service.Meta, key, value)
+		}
+		return nil
+	}
+}
+
+func TestAccConsulCatalogEntry_checks(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulNodeDestroy(consul, "appliance"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_checks,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulCatalogCheck(consul, "appliance", "appliance-ping", "", "passing"),
+					testAccCheckConsulCatalogCheck(consul, "appliance", "Admin UI", "admin", "warning"),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "check.#", "2"),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "check.1.name", "Admin UI"),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "check.1.service_id", "admin"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_checksUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulCatalogCheck(consul, "appliance", "Admin UI", "admin", "passing"),
+					testAccCheckConsulCatalogCheckRemoved(consul, "appliance", "appliance-ping"),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "check.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "consulclient_catalog_entry.app",
+				ImportState:       true,
+				ImportStateId:     "appliance",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulCatalogEntry_update(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	var modifyIndex uint64
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckConsulNodeExists(consul, "shared", "192.168.10.20"),
+			testAccCheckConsulCatalogEntryService(consul, "shared", "foreign", 7000),
+			testAccCheckConsulCatalogEntryServiceRemoved(consul, "shared", "redis1"),
+			testAccCheckConsulCatalogEntryServiceRemoved(consul, "shared", "web1"),
+		),
+		Steps: []resource.TestStep{
+			{
+				// A service registered on the node by someone else.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.nodes["shared"] = &fakeConsulNode{
+						node: &consulapi.Node{Node: "shared", Address: "192.168.10.20", Datacenter: "dc1"},
+						services: map[string]*consulapi.AgentService{
+							"foreign": {ID: "foreign", Service: "foreign", Port: 7000},
+						},
+						checks: make(map[string]*consulapi.HealthCheck),
+					}
+				},
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulCatalogEntryService(consul, "shared", "redis1", 8000),
+					testAccCheckConsulCatalogEntryService(consul, "shared", "web1", 80),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "id", "shared"),
+					func(s *terraform.State) error {
+						consul.lock.Lock()
+						defer consul.lock.Unlock()
+						modifyIndex = consul.nodes["shared"].services["redis1"].ModifyIndex
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_updated,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulCatalogEntryService(consul, "shared", "redis1", 8000),
+					testAccCheckConsulCatalogEntryService(consul, "shared", "web1", 8080),
+					testAccCheckConsulCatalogEntryService(consul, "shared", "foreign", 7000),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "id", "shared"),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "service.#", "2"),
+					func(s *terraform.State) error {
+						consul.lock.Lock()
+						defer consul.lock.Unlock()
+						if i := consul.nodes["shared"].services["redis1"].ModifyIndex; i != modifyIndex {
+							return fmt.Errorf("Unchanged service 'redis1' was registered again")
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulCatalogEntryConfig_removed,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulCatalogEntryService(consul, "shared", "redis1", 8000),
+					testAccCheckConsulCatalogEntryServiceRemoved(consul, "shared", "web1"),
+					testAccCheckConsulCatalogEntryService(consul, "shared", "foreign", 7000),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "id", "shared"),
+					resource.TestCheckResourceAttr("consulclient_catalog_entry.app", "service.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckConsulCatalogEntryServiceRemoved(consul *fakeConsul, node, id string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		if n, ok := consul.nodes[node]; ok {
+			if _, ok := n.services[id]; ok {
+				return fmt.Errorf("Service '%s' is still registered on node '%s'", id, node)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulCatalogEntryService(consul *fakeConsul, node, id string, port int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		n, ok := consul.nodes[node]
+		if !ok {
+			return fmt.Errorf("Node '%s' does not exist", node)
+		}
+		service, ok := n.services[id]
+		if !ok {
+			return fmt.Errorf("Service '%s' is not registered on node '%s'", id, node)
+		}
+		if service.Port != port {
+			return fmt.Errorf("Service '%s' has port %d; want %d", id, service.Port, port)
+		}
+		return nil
+	}
+}
+
+const testAccConsulCatalogEntryConfig = `
+resource "consulclient_catalog_entry" "app" {
+	address = "192.168.10.10"
+	node    = "foobar"
+
+	service {
+		id      = "redis1"
+		name    = "redis"
+		address = "127.0.0.1"
+		port    = 8000
+		tags    = ["master", "v1"]
+	}
+}
+`
+
+const testAccConsulCatalogEntryConfig_drift = `
+resource "consulclient_catalog_entry" "app" {
+	address = "192.168.10.10"
+	node    = "foobar"
+
+	service {
+		name = "redis"
+		port = 8000
+		tags = ["master"]
+	}
+
+	service {
+		id      = "web1"
+		name    = "web"
+		address = "127.0.0.1"
+		port    = 80
+	}
+}
+`
+
+const testAccConsulCatalogEntryConfig_checks = `
+resource "consulclient_catalog_entry" "app" {
+	address = "10.0.0.10"
+	node    = "appliance"
+
+	service {
+		id   = "admin"
+		name = "admin"
+		port = 443
+	}
+
+	check {
+		check_id = "appliance-ping"
+		name     = "Ping"
+		status   = "passing"
+		notes    = "Monitored externally"
+	}
+
+	check {
+		name       = "Admin UI"
+		service_id = "admin"
+		status     = "warning"
+		http       = "https://10.0.0.10/"
+		interval   = "1m"
+	}
+}
+`
+
+const testAccConsulCatalogEntryConfig_checksUpdate = `
+resource "consulclient_catalog_entry" "app" {
+	address = "10.0.0.10"
+	node    = "appliance"
+
+	service {
+		id   = "admin"
+		name = "admin"
+		port = 443
+	}
+
+	check {
+		name       = "Admin UI"
+		service_id = "admin"
+		status     = "passing"
+		http       = "https://10.0.0.10/"
+		interval   = "1m"
+	}
+}
+`
+
+const testAccConsulCatalogEntryConfig_update = `
+resource "consulclient_catalog_entry" "app" {
+	address = "192.168.10.20"
+	node    = "shared"
+
+	service {
+		id   = "redis1"
+		name = "redis"
+		port = 8000
+	}
+
+	service {
+		id   = "web1"
+		name = "web"
+		port = 80
+		tags = ["v1"]
+	}
+}
+`
+
+const testAccConsulCatalogEntryConfig_updated = `
+resource "consulclient_catalog_entry" "app" {
+	address = "192.168.10.20"
+	node    = "shared"
+
+	service {
+		id   = "redis1"
+		name = "redis"
+		port = 8000
+	}
+
+	service {
+		id   = "web1"
+		name = "web"
+		port = 8080
+		tags = ["v1", "v2"]
+	}
+}
+`
+
+const testAccConsulCatalogEntryConfig_removed = `
+resource "consulclient_catalog_entry" "app" {
+	address = "192.168.10.20"
+	node    = "shared"
+
+	service {
+		id   = "redis1"
+		name = "redis"
+		port = 8000
+	}
+}
+`
+
+const testAccConsulCatalogEntryConfig_meta = `
+resource "consulclient_catalog_entry" "app" {
+	address = "192.168.10.10"
+	node    = "foobar"
+
+	node_meta {
+		rack = "r1"
+	}
+
+	tagged_addresses {
+		lan = "192.168.10.10"
+	}
+
+	service {
+		id   = "redis1"
+		name = "redis"
+		port = 8000
+
+		meta {
+			version = "5"
+		}
+	}
+}
+`
+
+const testAccConsulCatalogEntryConfig_metaUpdate = `
+resource "consulclient_catalog_entry" "app" {
+	address = "192.168.10.10"
+	node    = "foobar"
+
+	node_meta {
+		rack = "r2"
+	}
+
+	tagged_addresses {
+		lan = "192.168.10.10"
+	}
+
+	service {
+		id                  = "redis1"
+		name                = "redis"
+		port                = 8000
+		enable_tag_override = true
+
+		meta {
+			version = "6"
+		}
+	}
+}
+`
+
+const testAccConsulCatalogEntryConfig_metaRemove = `
+resource "consulclient_catalog_entry" "app" {
+	address = "192.168.10.10"
+	node    = "foobar"
+
+	service {
+		id   = "redis1"
+		name = "redis"
+		port = 8000
+	}
+}
+`
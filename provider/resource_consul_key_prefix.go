@@ -5,10 +5,20 @@ package provider
 
 import (
 	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/hashicorp/terraform/helper/schema"
 )
 
+const (
+	keyPrefixOwnershipExclusive = "exclusive"
+	keyPrefixOwnershipPartial   = "partial"
+)
+
 func resourceConsulKeyPrefix() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceConsulKeyPrefixCreate,
@@ -16,6 +26,10 @@ func resourceConsulKeyPrefix() *schema.Resource {
 		Read:   resourceConsulKeyPrefixRead,
 		Delete: resourceConsulKeyPrefixDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulKeyPrefixImport,
+		},
+
 		Schema: map[string]*schema.Schema{
 			"host": {
 				Type:     schema.TypeString,
@@ -56,10 +70,9 @@ func resourceConsulKeyPrefix() *schema.Resource {
 				ForceNew: true,
 			},
 
-			"token": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
 
 			"path_prefix": {
 				Type:     schema.TypeString,
@@ -69,11 +82,104 @@ func resourceConsulKeyPrefix() *schema.Resource {
 
 			"subkeys": {
 				Type:     schema.TypeMap,
-				Required: true,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			// subkeys_base64 holds the subkeys whose values are not UTF-8
+			// text, base64-encoded.
+			"subkeys_base64": {
+				Type:             schema.TypeMap,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentKeyPrefixBase64,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
+
+			// sensitive_subkeys holds the subkeys whose values are kept
+			// out of plans and of the state, where only their hash is
+			// stored.
+			"sensitive_subkeys": {
+				Type:             schema.TypeMap,
+				Optional:         true,
+				Sensitive:        true,
+				DiffSuppressFunc: suppressSensitiveKeyPrefixSubKey,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			// sensitive_subkeys_base64 holds the sensitive subkeys whose
+			// values are not UTF-8 text, base64-encoded.
+			"sensitive_subkeys_base64": {
+				Type:             schema.TypeMap,
+				Optional:         true,
+				Sensitive:        true,
+				DiffSuppressFunc: suppressSensitiveKeyPrefixBase64,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			// subkey_flags holds the flags of the subkeys that have any,
+			// as decimal strings.
+			"subkey_flags": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+
+			// ownership is either "exclusive", to manage all the keys under
+			// path_prefix, or "partial", to manage only the subkeys given
+			// and leave the other keys under path_prefix alone.
+			"ownership": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  keyPrefixOwnershipExclusive,
+				ValidateFunc: makeValidationFunc("ownership", []interface{}{
+					validateRegexp(`^(exclusive|partial)$`),
+				}),
+			},
+
+			// adopt_existing makes the resource manage the subkeys given
+			// that already exist with the same value, rather than fail.
+			"adopt_existing": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			// purge_unmanaged makes the resource authoritative for the
+			// prefix with exclusive ownership: the keys under path_prefix
+			// that are not among the subkeys given are deleted on apply,
+			// including the ones written after the last refresh, and the
+			// prefix need not be empty when the resource is created. The
+			// keys found on refresh are read into subkeys, so the plan
+			// lists them as deleted subkeys.
+			"purge_unmanaged": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			// preserve lists glob patterns, as understood by path.Match,
+			// of the subkeys that purge_unmanaged leaves alone.
+			"preserve": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"lock": kvLockSchema(),
+
+			"cas": kvCASSchema(),
+
+			"modify_indexes": kvModifyIndexesSchema(),
 		},
 	}
 }
@@ -89,34 +195,62 @@ func resourceConsulKeyPrefixCreate(d *schema.ResourceData, meta interface{}) err
 		return err
 	}
 	kv := client.KV()
-	token := d.Get("token").(string)
-	dc, err := getDC(d, client)
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
 
 	keyClient := newKeyClient(kv, dc, token)
+	keyClient.cas = d.Get("cas").(bool)
+	keyClient.indexes = expandModifyIndexes(d)
+
+	unlock, err := acquireKVLock(client, d)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	pathPrefix := d.Get("path_prefix").(string)
-	subKeys := map[string]string{}
-	for k, vI := range d.Get("subkeys").(map[string]interface{}) {
-		subKeys[k] = vI.(string)
+	subKeys, err := expandKeyPrefixSubKeys(d.Get, false)
+	if err != nil {
+		return err
+	}
+	flags, err := expandKeyPrefixSubKeyFlags(d.Get("subkey_flags"), subKeys)
+	if err != nil {
+		return err
 	}
 
 	// To reduce the impact of mistakes, we will only "create" a prefix that
 	// is currently empty. This way we are less likely to accidentally
-	// conflict with other mechanisms managing the same prefix.
+	// conflict with other mechanisms managing the same prefix. With partial
+	// ownership, only the subkeys we manage need to be absent, unless they
+	// are adopted.
 	currentSubKeys, err := keyClient.GetUnderPrefix(pathPrefix)
 	if err != nil {
 		return err
 	}
-	if len(currentSubKeys) > 0 {
+	partial := d.Get("ownership").(string) == keyPrefixOwnershipPartial
+	purge, preserve, err := keyPrefixPurgeOptions(d)
+	if err != nil {
+		return err
+	}
+	if !partial && !purge && len(currentSubKeys) > 0 {
 		return fmt.Errorf(
-			"%d keys already exist under %s; delete them before managing this prefix with Terraform",
+			"%d keys already exist under %s; delete them before managing this prefix with Terraform, or set purge_unmanaged to delete them",
 			len(currentSubKeys), pathPrefix,
 		)
 	}
+	var adopted map[string]bool
+	if partial {
+		adopted, err = adoptKeyPrefixSubKeys(pathPrefix, subKeys, currentSubKeys, d.Get("adopt_existing").(bool))
+		if err != nil {
+			return err
+		}
+	}
 
+	// The keys are written in a single transaction, but one that is too
+	// large is split in several, so a partial write is still possible.
 	// Ideally we'd use d.Partial(true) here so we can correctly record
 	// a partial write, but that mechanism doesn't work for individual map
 	// members, so we record that the resource was created before we
@@ -133,15 +267,27 @@ func resourceConsulKeyPrefixCreate(d *schema.ResourceData, meta interface{}) err
 	// that nothing should need deleting yet, as long as there isn't some
 	// other program racing us to write values... which we'll catch on a
 	// subsequent Read.
-	for k, v := range subKeys {
-		fullPath := pathPrefix + k
-		err := keyClient.Put(fullPath, v)
-		if err != nil {
-			return fmt.Errorf("error while writing %s: %s", fullPath, err)
+	txn := keyClient.NewTxn()
+	for _, k := range sortedKeys(subKeys) {
+		if adopted[k] && keyClient.flags[pathPrefix+k] == flags[k] {
+			txn.Check(pathPrefix + k)
+			continue
 		}
+		txn.Set(pathPrefix+k, subKeys[k].(string), flags[k])
+	}
+	if purge {
+		for _, k := range sortedStringKeys(currentSubKeys) {
+			if _, ok := subKeys[k]; ok || isPreservedKeyPrefixSubKey(k, preserve) {
+				continue
+			}
+			txn.Delete(pathPrefix + k)
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		return fmt.Errorf("error while writing %s: %s", pathPrefix, err)
 	}
 
-	return nil
+	return resourceConsulKeyPrefixRead(d, meta)
 }
 
 func resourceConsulKeyPrefixUpdate(d *schema.ResourceData, meta interface{}) error {
@@ -155,67 +301,148 @@ func resourceConsulKeyPrefixUpdate(d *schema.ResourceData, meta interface{}) err
 		return err
 	}
 	kv := client.KV()
-	token := d.Get("token").(string)
-	dc, err := getDC(d, client)
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
 
 	keyClient := newKeyClient(kv, dc, token)
+	keyClient.cas = d.Get("cas").(bool)
+	keyClient.indexes = expandModifyIndexes(d)
+
+	unlock, err := acquireKVLock(client, d)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	pathPrefix := d.Id()
+	purge, preserve, err := keyPrefixPurgeOptions(d)
+	if err != nil {
+		return err
+	}
 
-	if d.HasChange("subkeys") {
-		o, n := d.GetChange("subkeys")
-		if o == nil {
-			o = map[string]interface{}{}
+	// The unmanaged keys are purged on every apply, as some may have been
+	// written since the last refresh.
+	changed := d.HasChange("subkey_flags") || d.HasChange("preserve")
+	for _, attr := range keyPrefixSubKeyMaps {
+		changed = changed || d.HasChange(attr)
+	}
+	if purge || changed {
+		old := func(k string) interface{} {
+			o, _ := d.GetChange(k)
+			return o
+		}
+		oFlags, nFlags := d.GetChange("subkey_flags")
+
+		// The old values of the sensitive subkeys are the hashes last
+		// read, which are compared with the hashes of the new values.
+		sensitive := sensitiveKeyPrefixSubKeys(old)
+		om, err := expandKeyPrefixSubKeys(old, true)
+		if err != nil {
+			return err
 		}
-		if n == nil {
-			n = map[string]interface{}{}
+		nm, err := expandKeyPrefixSubKeys(d.Get, false)
+		if err != nil {
+			return err
+		}
+		// The old flags are the ones last read, which may include
+		// subkeys that are no longer in om.
+		of, err := expandKeyPrefixSubKeyFlags(oFlags, nil)
+		if err != nil {
+			return err
+		}
+		nf, err := expandKeyPrefixSubKeyFlags(nFlags, nm)
+		if err != nil {
+			return err
 		}
 
-		om := o.(map[string]interface{})
-		nm := n.(map[string]interface{})
+		// With partial ownership, the subkeys we start managing may
+		// already exist, as when creating the resource.
+		if d.Get("ownership").(string) == keyPrefixOwnershipPartial {
+			current := make(map[string]string)
+			for k := range nm {
+				if _, exists := om[k]; exists {
+					continue
+				}
+				value, err := keyClient.Get(pathPrefix + k)
+				if err != nil {
+					return err
+				}
+				if _, exists := keyClient.indexes[pathPrefix+k]; exists {
+					current[k] = value
+				}
+			}
+			if _, err := adoptKeyPrefixSubKeys(pathPrefix, nm, current, d.Get("adopt_existing").(bool)); err != nil {
+				// Nothing was written, so the state is kept as it was
+				// rather than recording the subkeys as managed.
+				d.Partial(true)
+				return err
+			}
+		}
 
-		// First we'll write all of the stuff in the "new map" nm,
-		// and then we'll delete any keys that appear in the "old map" om
-		// and do not also appear in nm. This ordering means that if a subkey
-		// name is changed we will briefly have both the old and new names in
-		// Consul, as opposed to briefly having neither.
+		// The new and changed keys of the "new map" nm are written and
+		// the keys that appear in the "old map" om but not in nm are
+		// deleted in a single transaction, so that the prefix is never
+		// left half-updated. In CAS mode the unchanged keys are checked
+		// too, so that nothing is written if any of the keys changed
+		// since they were read.
 
-		// Again, we'd ideally use d.Partial(true) here but it doesn't work
-		// for maps and so we'll just rely on a subsequent Read to tidy up
-		// after a partial write.
+		// A transaction that is too large is still split in several, in
+		// which case we'll rely on a subsequent Read to tidy up after a
+		// partial write, as we'd ideally use d.Partial(true) here but it
+		// doesn't work for maps.
+		txn := keyClient.NewTxn()
 
 		// Write new and changed keys
-		for k, vI := range nm {
-			v := vI.(string)
-			fullPath := pathPrefix + k
-			err := keyClient.Put(fullPath, v)
-			if err != nil {
-				return fmt.Errorf("error while writing %s: %s", fullPath, err)
+		for _, k := range sortedKeys(nm) {
+			v := nm[k].(string)
+			if old, exists := om[k]; exists && equalKeyPrefixSubKeyValues(old.(string), v, sensitive[k]) && of[k] == nf[k] {
+				txn.Check(pathPrefix + k)
+				continue
 			}
+			txn.Set(pathPrefix+k, v, nf[k])
 		}
 
 		// Remove deleted keys
-		for k := range om {
+		for _, k := range sortedKeys(om) {
 			if _, exists := nm[k]; exists {
 				continue
 			}
-			fullPath := pathPrefix + k
-			err := keyClient.Delete(fullPath)
+			txn.Delete(pathPrefix + k)
+		}
+
+		// Remove the unmanaged keys, including the ones written since
+		// the last refresh.
+		if purge {
+			paths, err := keyClient.GetKeysUnderPrefix(pathPrefix, "")
 			if err != nil {
-				return fmt.Errorf("error while deleting %s: %s", fullPath, err)
+				return err
+			}
+			for _, path := range paths {
+				k := path[len(pathPrefix):]
+				if _, exists := nm[k]; exists {
+					continue
+				}
+				if _, exists := om[k]; exists || isPreservedKeyPrefixSubKey(k, preserve) {
+					continue
+				}
+				log.Printf("[DEBUG] Purging unmanaged key '%s'", path)
+				txn.Delete(path)
 			}
 		}
 
+		if err := txn.Commit(); err != nil {
+			return fmt.Errorf("error while writing %s: %s", pathPrefix, err)
+		}
 	}
 
 	// Store the datacenter on this resource, which can be helpful for reference
 	// in case it was read from the provider
 	d.Set("datacenter", dc)
 
-	return nil
+	return resourceConsulKeyPrefixRead(d, meta)
 }
 
 func resourceConsulKeyPrefixRead(d *schema.ResourceData, meta interface{}) error {
@@ -229,8 +456,8 @@ func resourceConsulKeyPrefixRead(d *schema.ResourceData, meta interface{}) error
 		return err
 	}
 	kv := client.KV()
-	token := d.Get("token").(string)
-	dc, err := getDC(d, client)
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
@@ -239,12 +466,70 @@ func resourceConsulKeyPrefixRead(d *schema.ResourceData, meta interface{}) error
 
 	pathPrefix := d.Id()
 
-	subKeys, err := keyClient.GetUnderPrefix(pathPrefix)
+	values, err := keyClient.GetUnderPrefix(pathPrefix)
+	if err != nil {
+		return err
+	}
+
+	managed, err := keyPrefixSubKeyNames(d)
+	if err != nil {
+		return err
+	}
+	purge, preserve, err := keyPrefixPurgeOptions(d)
 	if err != nil {
 		return err
 	}
 
+	// With partial ownership, the keys we don't manage are ignored, as are
+	// the preserved ones when purging.
+	partial := d.Get("ownership").(string) == keyPrefixOwnershipPartial
+	for k := range values {
+		ok := managed[k]
+		if (partial && !ok) || (purge && !ok && isPreservedKeyPrefixSubKey(k, preserve)) {
+			delete(values, k)
+			delete(keyClient.indexes, pathPrefix+k)
+			delete(keyClient.flags, pathPrefix+k)
+		}
+	}
+
+	// With exclusive ownership, the keys we don't manage are read into
+	// subkeys too, so that the plan lists them as deleted subkeys. This is
+	// how the keys that purge_unmanaged deletes show up at plan time.
+	if !partial {
+		var unmanaged []string
+		for _, k := range sortedStringKeys(values) {
+			if !managed[k] {
+				unmanaged = append(unmanaged, k)
+			}
+		}
+		if len(unmanaged) > 0 {
+			log.Printf("[INFO] Keys under '%s' not managed by Terraform: %s", pathPrefix, strings.Join(unmanaged, ", "))
+		}
+	}
+
+	// The sensitive subkeys are stored as the hashes of their values.
+	oldSensitive := d.Get("sensitive_subkeys").(map[string]interface{})
+	oldSensitiveBase64 := d.Get("sensitive_subkeys_base64").(map[string]interface{})
+	sensitiveSubKeys := make(map[string]string)
+	sensitiveSubKeysBase64 := make(map[string]string)
+	for k, v := range values {
+		if _, ok := oldSensitive[k]; ok {
+			sensitiveSubKeys[k] = sensitiveValueHash(v)
+			delete(values, k)
+		} else if _, ok := oldSensitiveBase64[k]; ok {
+			sensitiveSubKeysBase64[k] = sensitiveValueHash(v)
+			delete(values, k)
+		}
+	}
+	subKeys, subKeysBase64 := flattenKeyPrefixSubKeys(values, d.Get("subkeys_base64").(map[string]interface{}))
+
+	d.Set("path_prefix", pathPrefix)
 	d.Set("subkeys", subKeys)
+	d.Set("subkeys_base64", subKeysBase64)
+	d.Set("sensitive_subkeys", sensitiveSubKeys)
+	d.Set("sensitive_subkeys_base64", sensitiveSubKeysBase64)
+	d.Set("subkey_flags", flattenKeyPrefixSubKeyFlags(pathPrefix, keyClient.flags, d.Get("subkey_flags").(map[string]interface{})))
+	d.Set("modify_indexes", flattenModifyIndexes(keyClient.indexes))
 
 	// Store the datacenter on this resource, which can be helpful for reference
 	// in case it was read from the provider
@@ -264,24 +549,309 @@ func resourceConsulKeyPrefixDelete(d *schema.ResourceData, meta interface{}) err
 		return err
 	}
 	kv := client.KV()
-	token := d.Get("token").(string)
-	dc, err := getDC(d, client)
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
 
 	keyClient := newKeyClient(kv, dc, token)
+	keyClient.cas = d.Get("cas").(bool)
+	keyClient.indexes = expandModifyIndexes(d)
+
+	unlock, err := acquireKVLock(client, d)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	pathPrefix := d.Id()
 
-	// Delete everything under our prefix, since the entire set of keys under
-	// the given prefix is considered to be managed exclusively by Terraform.
-	err = keyClient.DeleteUnderPrefix(pathPrefix)
-	if err != nil {
+	if d.Get("ownership").(string) == keyPrefixOwnershipPartial {
+		// Delete only the subkeys we manage.
+		subKeys, err := keyPrefixSubKeyNames(d)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(subKeys))
+		for k := range subKeys {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		txn := keyClient.NewTxn()
+		for _, k := range names {
+			txn.Delete(pathPrefix + k)
+		}
+		if err := txn.Commit(); err != nil {
+			return fmt.Errorf("error while deleting keys under %s: %s", pathPrefix, err)
+		}
+	} else if purge, preserve, err := keyPrefixPurgeOptions(d); err != nil {
 		return err
+	} else if purge && len(preserve) > 0 {
+		// Delete everything under our prefix but the preserved keys.
+		paths, err := keyClient.GetKeysUnderPrefix(pathPrefix, "")
+		if err != nil {
+			return err
+		}
+		txn := keyClient.NewTxn()
+		for _, path := range paths {
+			if isPreservedKeyPrefixSubKey(path[len(pathPrefix):], preserve) {
+				continue
+			}
+			txn.Delete(path)
+		}
+		if err := txn.Commit(); err != nil {
+			return fmt.Errorf("error while deleting keys under %s: %s", pathPrefix, err)
+		}
+	} else {
+		// Delete everything under our prefix, since the entire set of keys under
+		// the given prefix is considered to be managed exclusively by Terraform.
+		err = keyClient.DeleteUnderPrefix(pathPrefix)
+		if err != nil {
+			return err
+		}
 	}
 
 	d.SetId("")
 
 	return nil
 }
+
+// resourceConsulKeyPrefixImport imports the keys under the path prefix given
+// as ID, which are then read by Read.
+func resourceConsulKeyPrefixImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := parseImportTarget(d); err != nil {
+		return nil, err
+	}
+	if err := setImportDefaults(d, resourceConsulKeyPrefix().Schema); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// keyPrefixPurgeOptions returns whether the unmanaged keys are purged and
+// the patterns of the preserved ones.
+func keyPrefixPurgeOptions(d *schema.ResourceData) (bool, []string, error) {
+	purge := d.Get("purge_unmanaged").(bool)
+	if purge && d.Get("ownership").(string) == keyPrefixOwnershipPartial {
+		return false, nil, fmt.Errorf("purge_unmanaged cannot be set with partial ownership")
+	}
+	preserve, err := expandKeyPrefixPatterns(d.Get("preserve").([]interface{}))
+	if err != nil {
+		return false, nil, err
+	}
+	return purge, preserve, nil
+}
+
+// isPreservedKeyPrefixSubKey returns whether subKey matches one of the
+// preserve patterns.
+func isPreservedKeyPrefixSubKey(subKey string, preserve []string) bool {
+	return !matchKeyPrefixPatterns(subKey, nil, preserve)
+}
+
+// adoptKeyPrefixSubKeys checks the subkeys that already exist with the
+// current values given, and returns the ones that are adopted as they hold
+// the same value as in subKeys. It fails if any other exists.
+func adoptKeyPrefixSubKeys(pathPrefix string, subKeys map[string]interface{}, current map[string]string, adopt bool) (map[string]bool, error) {
+	adopted := make(map[string]bool)
+	for _, k := range sortedKeys(subKeys) {
+		value, exists := current[k]
+		if !exists {
+			continue
+		}
+		if !adopt {
+			return nil, fmt.Errorf(
+				"Key '%s' already exists; delete it or set adopt_existing to manage it with Terraform",
+				pathPrefix+k,
+			)
+		}
+		if value != subKeys[k].(string) {
+			return nil, fmt.Errorf(
+				"Key '%s' already exists with a different value; it can only be adopted if its value matches",
+				pathPrefix+k,
+			)
+		}
+		adopted[k] = true
+	}
+	return adopted, nil
+}
+
+// keyPrefixSubKeyMaps are the attributes holding the values of the subkeys,
+// each of which can be in only one of them.
+var keyPrefixSubKeyMaps = []string{"subkeys", "subkeys_base64", "sensitive_subkeys", "sensitive_subkeys_base64"}
+
+// expandKeyPrefixSubKeys returns the values of the subkeys given by the
+// keyPrefixSubKeyMaps attributes, as returned by get, decoding the base64
+// ones. When hashed, the values are the ones stored in state, where the
+// sensitive subkeys hold the hashes of their values, which are kept as they
+// are.
+func expandKeyPrefixSubKeys(get func(string) interface{}, hashed bool) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, attr := range keyPrefixSubKeyMaps {
+		m, _ := get(attr).(map[string]interface{})
+		for k, v := range m {
+			if _, ok := values[k]; ok {
+				return nil, keyPrefixSubKeyConflictError(k)
+			}
+			value := v.(string)
+			if hashed && strings.HasPrefix(attr, "sensitive_") || !strings.HasSuffix(attr, "_base64") {
+				values[k] = value
+				continue
+			}
+			value, err := decodeBase64Value(value)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to decode subkey '%s': %v", k, err)
+			}
+			values[k] = value
+		}
+	}
+	return values, nil
+}
+
+// keyPrefixSubKeyNames returns the set of the subkeys of d, in any of the
+// keyPrefixSubKeyMaps attributes, without decoding their values.
+func keyPrefixSubKeyNames(d *schema.ResourceData) (map[string]bool, error) {
+	names := make(map[string]bool)
+	for _, attr := range keyPrefixSubKeyMaps {
+		for k := range d.Get(attr).(map[string]interface{}) {
+			if names[k] {
+				return nil, keyPrefixSubKeyConflictError(k)
+			}
+			names[k] = true
+		}
+	}
+	return names, nil
+}
+
+func keyPrefixSubKeyConflictError(k string) error {
+	return fmt.Errorf("Subkey '%s' can only be set in one of %s", k, strings.Join(keyPrefixSubKeyMaps, ", "))
+}
+
+// sensitiveKeyPrefixSubKeys returns the set of the sensitive subkeys, in
+// the sensitive_subkeys and sensitive_subkeys_base64 maps as returned by get.
+func sensitiveKeyPrefixSubKeys(get func(string) interface{}) map[string]bool {
+	sensitive := make(map[string]bool)
+	for _, attr := range []string{"sensitive_subkeys", "sensitive_subkeys_base64"} {
+		m, _ := get(attr).(map[string]interface{})
+		for k := range m {
+			sensitive[k] = true
+		}
+	}
+	return sensitive
+}
+
+// equalKeyPrefixSubKeyValues returns whether the value old of a subkey, as
+// last read, is new, as configured. The old value of a sensitive subkey is
+// the hash of the value.
+func equalKeyPrefixSubKeyValues(old, new string, sensitive bool) bool {
+	if sensitive {
+		return old == sensitiveValueHash(new)
+	}
+	return old == new
+}
+
+// isConfiguredKeyPrefixSubKey returns whether the subkey whose value is
+// diffed under the attribute k is still configured, rather than removed.
+func isConfiguredKeyPrefixSubKey(k string, d *schema.ResourceData) bool {
+	i := strings.Index(k, ".")
+	if k[i+1:] == "%" {
+		return false
+	}
+	_, ok := d.Get(k[:i]).(map[string]interface{})[k[i+1:]]
+	return ok
+}
+
+// suppressSensitiveKeyPrefixSubKey suppresses the differences between the
+// hash stored for a sensitive subkey and the value it was computed from.
+func suppressSensitiveKeyPrefixSubKey(k, old, new string, d *schema.ResourceData) bool {
+	return isConfiguredKeyPrefixSubKey(k, d) && equalKeyPrefixSubKeyValues(old, new, true)
+}
+
+// suppressSensitiveKeyPrefixBase64 suppresses the differences between the
+// hash stored for a sensitive subkey and the base64 value it was computed
+// from.
+func suppressSensitiveKeyPrefixBase64(k, old, new string, d *schema.ResourceData) bool {
+	return isConfiguredKeyPrefixSubKey(k, d) && old == sensitiveBase64Hash(new)
+}
+
+// suppressEquivalentKeyPrefixBase64 suppresses the differences between
+// base64 strings that encode the same bytes.
+func suppressEquivalentKeyPrefixBase64(k, old, new string, d *schema.ResourceData) bool {
+	if strings.HasSuffix(k, ".%") {
+		return false
+	}
+	return base64ContentHash(old) == base64ContentHash(new)
+}
+
+// expandKeyPrefixSubKeyFlags returns the flags given by the subkey_flags
+// map. Unless values is nil, each of them must be the flags of one of the
+// subkeys in values.
+func expandKeyPrefixSubKeyFlags(subKeyFlags interface{}, values map[string]interface{}) (map[string]uint64, error) {
+	flags := make(map[string]uint64)
+	m, _ := subKeyFlags.(map[string]interface{})
+	for k, v := range m {
+		if _, ok := values[k]; values != nil && !ok {
+			return nil, fmt.Errorf("Subkey '%s' has flags in subkey_flags but no value in %s", k, strings.Join(keyPrefixSubKeyMaps, ", "))
+		}
+		f, err := strconv.ParseUint(v.(string), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse flags of subkey '%s': %v", k, err)
+		}
+		flags[k] = f
+	}
+	return flags, nil
+}
+
+// flattenKeyPrefixSubKeyFlags returns the flags of the keys under
+// pathPrefix as stored in subkey_flags, which are the non-zero ones and the
+// ones already there.
+func flattenKeyPrefixSubKeyFlags(pathPrefix string, flags map[string]uint64, oldFlags map[string]interface{}) map[string]string {
+	subKeyFlags := make(map[string]string)
+	for path, f := range flags {
+		if !strings.HasPrefix(path, pathPrefix) {
+			continue
+		}
+		k := path[len(pathPrefix):]
+		if _, ok := oldFlags[k]; ok || f != 0 {
+			subKeyFlags[k] = strconv.FormatUint(f, 10)
+		}
+	}
+	return subKeyFlags
+}
+
+// flattenKeyPrefixSubKeys splits the values of the subkeys into the ones
+// stored in subkeys and the ones stored base64-encoded in subkeys_base64,
+// which are the subkeys already there and the ones that are not text.
+func flattenKeyPrefixSubKeys(values map[string]string, oldBase64 map[string]interface{}) (map[string]string, map[string]string) {
+	subKeys := make(map[string]string)
+	subKeysBase64 := make(map[string]string)
+	for k, v := range values {
+		if _, ok := oldBase64[k]; ok || !utf8.ValidString(v) {
+			subKeysBase64[k] = encodeBase64Value(v)
+			continue
+		}
+		subKeys[k] = v
+	}
+	return subKeys, subKeysBase64
+}
+
+// sortedKeys returns the keys of m in order, so that the writes to the
+// subkeys are issued in a stable order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedStringKeys returns the keys of m in order.
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
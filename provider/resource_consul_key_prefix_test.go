@@ -0,0 +1,759 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulKeyPrefix_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckConsulKeysRemoved(consul, "prefix_test/cheese", "prefix_test/bread", "prefix_test/meat"),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/cheese", "chevre"),
+					testAccCheckConsulKeysValue(consul, "prefix_test/bread", "baguette"),
+					testAccCheckConsulKeysRemoved(consul, "prefix_test/meat"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkeys.%", "2"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkeys.cheese", "chevre"),
+				),
+			},
+			{
+				// Drift in Consul shows up as a diff.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.kv["prefix_test/cheese"].Value = []byte("cheddar")
+				},
+				Config:             testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/meat", "ham"),
+					testAccCheckConsulKeysValue(consul, "prefix_test/bread", "batard"),
+					testAccCheckConsulKeysRemoved(consul, "prefix_test/cheese"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkeys.%", "2"),
+				),
+			},
+			{
+				ResourceName:      "consulclient_key_prefix.app",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyPrefix_lockWithSession(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckConsulKeysRemoved(consul, "prefix_test/cheese", "prefix_test/bread"),
+			testAccCheckConsulSessionDestroy(consul),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig_lock,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/cheese", "chevre"),
+					testAccCheckConsulKeyLockReleased(consul, "locks/prefix"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "modify_indexes.%", "2"),
+					func(s *terraform.State) error {
+						consul.lock.Lock()
+						defer consul.lock.Unlock()
+						if len(consul.sessions) != 1 {
+							return fmt.Errorf("expected the lock to use the given session, got %d sessions", len(consul.sessions))
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyPrefix_manyKeys(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckConsulKeysRemoved(consul, "prefix_test/key00", "prefix_test/key69"),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig_manyKeys(70, "one"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/key00", "one"),
+					testAccCheckConsulKeysValue(consul, "prefix_test/key69", "one"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkeys.%", "70"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "modify_indexes.%", "70"),
+				),
+			},
+			{
+				// Updating 60 keys and removing 10 takes more operations
+				// than fit in a single transaction.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.txns = 0
+				},
+				Config: testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig_manyKeys(60, "two"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/key00", "two"),
+					testAccCheckConsulKeysValue(consul, "prefix_test/key59", "two"),
+					testAccCheckConsulKeysRemoved(consul, "prefix_test/key60", "prefix_test/key69"),
+					func(s *terraform.State) error {
+						consul.lock.Lock()
+						defer consul.lock.Unlock()
+						if consul.txns != 2 {
+							return fmt.Errorf("expected the writes to be split in 2 transactions, got %d", consul.txns)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyPrefix_base64(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul, "prefix_test/cheese", "prefix_test/blob"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_base64, "AAH/"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/cheese", "chevre"),
+					testAccCheckConsulKeysValue(consul, "prefix_test/blob", "\x00\x01\xff"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkeys.%", "1"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkeys_base64.%", "1"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkeys_base64.blob", "AAH/"),
+				),
+			},
+			{
+				// An equivalent encoding is not a change.
+				Config:   testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_base64, " AAH/"),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_base64, "/w=="),
+				Check:  testAccCheckConsulKeysValue(consul, "prefix_test/blob", "\xff"),
+			},
+			{
+				// Values that are not text are imported base64-encoded.
+				ResourceName:      "consulclient_key_prefix.app",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyPrefix_flags(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul, "prefix_test/cheese", "prefix_test/bread"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_flags, "42"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysFlags(consul, "prefix_test/cheese", 42),
+					testAccCheckConsulKeysFlags(consul, "prefix_test/bread", 0),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkey_flags.%", "1"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkey_flags.cheese", "42"),
+				),
+			},
+			{
+				// Drift of the flags in Consul shows up as a diff.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.kv["prefix_test/bread"].Flags = 7
+				},
+				Config:             testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_flags, "42"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_flags, "43"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/cheese", "chevre"),
+					testAccCheckConsulKeysFlags(consul, "prefix_test/cheese", 43),
+					testAccCheckConsulKeysFlags(consul, "prefix_test/bread", 0),
+				),
+			},
+			{
+				ResourceName:      "consulclient_key_prefix.app",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyPrefix_flagsWithoutValue(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig_flagsWithoutValue,
+				ExpectError: regexp.MustCompile("Subkey 'wine' has flags in subkey_flags but no value"),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyPrefix_partialOwnership(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	consul.kv["prefix_test/foreign"] = &consulapi.KVPair{Key: "prefix_test/foreign", Value: []byte("app"), ModifyIndex: 1}
+	consul.kv["prefix_test/cheese"] = &consulapi.KVPair{Key: "prefix_test/cheese", Value: []byte("cheddar"), ModifyIndex: 1}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckConsulKeysRemoved(consul, "prefix_test/cheese", "prefix_test/meat"),
+			testAccCheckConsulKeysValue(consul, "prefix_test/foreign", "app"),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_partial, false),
+				ExpectError: regexp.MustCompile("Key 'prefix_test/cheese' already exists; delete it or set adopt_existing"),
+			},
+			{
+				Config:      testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_partial, true),
+				ExpectError: regexp.MustCompile("Key 'prefix_test/cheese' already exists with a different value"),
+			},
+			{
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.kv["prefix_test/cheese"].Value = []byte("chevre")
+				},
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_partial, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/cheese", "chevre"),
+					testAccCheckConsulKeysValue(consul, "prefix_test/bread", "baguette"),
+					testAccCheckConsulKeysValue(consul, "prefix_test/foreign", "app"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkeys.%", "2"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "modify_indexes.%", "2"),
+				),
+			},
+			{
+				// Keys written by others are ignored.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.kv["prefix_test/other"] = &consulapi.KVPair{Key: "prefix_test/other", Value: []byte("app"), ModifyIndex: 1}
+				},
+				Config:   testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_partial, true),
+				PlanOnly: true,
+			},
+			{
+				// Removing a subkey only deletes that one.
+				Config: testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig_partialUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/meat", "ham"),
+					testAccCheckConsulKeysRemoved(consul, "prefix_test/bread"),
+					testAccCheckConsulKeysValue(consul, "prefix_test/foreign", "app"),
+					testAccCheckConsulKeysValue(consul, "prefix_test/other", "app"),
+				),
+			},
+			{
+				// Starting to manage a key written by others needs adopting it.
+				Config:      testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig_partialForeign,
+				ExpectError: regexp.MustCompile("Key 'prefix_test/foreign' already exists; delete it or set adopt_existing"),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyPrefix_purgeUnmanaged(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	consul.kv["prefix_test/stray"] = &consulapi.KVPair{Key: "prefix_test/stray", Value: []byte("x"), ModifyIndex: 1}
+	consul.kv["prefix_test/keep/me"] = &consulapi.KVPair{Key: "prefix_test/keep/me", Value: []byte("y"), ModifyIndex: 1}
+
+	var state *terraform.State
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckConsulKeysRemoved(consul, "prefix_test/cheese", "prefix_test/late"),
+			testAccCheckConsulKeysValue(consul, "prefix_test/keep/me", "y"),
+		),
+		Steps: []resource.TestStep{
+			{
+				// Existing keys are purged on create, but the preserved ones.
+				Config: testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig_purge,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/cheese", "chevre"),
+					testAccCheckConsulKeysRemoved(consul, "prefix_test/stray"),
+					testAccCheckConsulKeysValue(consul, "prefix_test/keep/me", "y"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkeys.%", "1"),
+					func(s *terraform.State) error {
+						state = s
+						return nil
+					},
+				),
+			},
+			{
+				// Keys written by others are read into subkeys on refresh,
+				// and show up as deleted in the plan.
+				PreConfig: func() {
+					consul.lock.Lock()
+					consul.kv["prefix_test/late"] = &consulapi.KVPair{Key: "prefix_test/late", Value: []byte("z"), ModifyIndex: 1}
+					consul.lock.Unlock()
+					if err := testAccCheckConsulKeyPrefixRefreshedSubKeys("consulclient_key_prefix.app", "cheese", "late")(state); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig_purge,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig_purge,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysRemoved(consul, "prefix_test/late"),
+					testAccCheckConsulKeysValue(consul, "prefix_test/keep/me", "y"),
+				),
+			},
+			{
+				// Keys written since the refresh are purged by any update,
+				// even one that leaves the subkeys alone.
+				PreConfig: func() {
+					consul.lock.Lock()
+					consul.kv["prefix_test/later"] = &consulapi.KVPair{Key: "prefix_test/later", Value: []byte("z"), ModifyIndex: 1}
+					consul.lock.Unlock()
+					diff := &terraform.InstanceDiff{
+						Attributes: map[string]*terraform.ResourceAttrDiff{
+							"adopt_existing": {Old: "false", New: "true"},
+						},
+					}
+					check := resource.ComposeTestCheckFunc(
+						testAccConsulKeyPrefixApply("consulclient_key_prefix.app", diff),
+						testAccCheckConsulKeysRemoved(consul, "prefix_test/later"),
+						testAccCheckConsulKeysValue(consul, "prefix_test/cheese", "chevre"),
+						testAccCheckConsulKeysValue(consul, "prefix_test/keep/me", "y"),
+					)
+					if err := check(state); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:   testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig_purge,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyPrefix_sensitive(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul, "prefix_test/user", "prefix_test/password"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_sensitive, "admin", "hunter2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/password", "hunter2"),
+					testAccCheckStateDoesNotContain("consulclient_key_prefix.app", "hunter2"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkeys.user", "admin"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "sensitive_subkeys.password", sensitiveValueHash("hunter2")),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_sensitive, "admin", "hunter3"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/password", "hunter3"),
+					testAccCheckStateDoesNotContain("consulclient_key_prefix.app", "hunter3"),
+				),
+			},
+			{
+				// Values that look like the stored hashes are still
+				// hashed when sensitive, and kept as they are otherwise.
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_sensitive, "sha256:0123", "sha256:hunter2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/user", "sha256:0123"),
+					testAccCheckConsulKeysValue(consul, "prefix_test/password", "sha256:hunter2"),
+					testAccCheckStateDoesNotContain("consulclient_key_prefix.app", "sha256:hunter2"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "subkeys.user", "sha256:0123"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "sensitive_subkeys.password", sensitiveValueHash("sha256:hunter2")),
+				),
+			},
+			{
+				// Removing a sensitive subkey deletes it.
+				Config: testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig_sensitiveRemoved,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysRemoved(consul, "prefix_test/password"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "sensitive_subkeys.%", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyPrefix_sensitiveBase64(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul, "prefix_test/cert"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_sensitiveBase64, "AAH/"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/cert", "\x00\x01\xff"),
+					testAccCheckStateDoesNotContain("consulclient_key_prefix.app", "AAH/"),
+					resource.TestCheckResourceAttr("consulclient_key_prefix.app", "sensitive_subkeys_base64.cert", sensitiveValueHash("\x00\x01\xff")),
+				),
+			},
+			{
+				Config:   testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_sensitiveBase64, "AAH/"),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyPrefixConfig_sensitiveBase64, "AAL/"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "prefix_test/cert", "\x00\x02\xff"),
+					testAccCheckStateDoesNotContain("consulclient_key_prefix.app", "AAL/"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyPrefix_existingKeys(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.kv["prefix_test/foreign"] = &consulapi.KVPair{Key: "prefix_test/foreign"}
+				},
+				Config:      testAccProviderConfig(consul) + testAccConsulKeyPrefixConfig,
+				ExpectError: regexp.MustCompile("1 keys already exist under prefix_test/"),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyPrefix_importFromOtherEndpoint(t *testing.T) {
+	primary := newFakeConsul("dc1")
+	defer primary.Close()
+	defer testAccProviderEnv(primary)()
+	secondary := newFakeConsul("dc2")
+	defer secondary.Close()
+
+	config := testAccProviderConfig(primary) + fmt.Sprintf(testAccConsulKeyPrefixConfig_host, secondary.Address())
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(secondary, "prefix_test/cheese", "chevre"),
+					testAccCheckConsulKeysRemoved(primary, "prefix_test/cheese"),
+				),
+			},
+			{
+				ResourceName:      "consulclient_key_prefix.app",
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("http://%s/dc2/prefix_test/", secondary.Address()),
+				ImportStateVerify: true,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected 1 state, got %d", len(states))
+					}
+					if id := states[0].ID; id != "prefix_test/" {
+						return fmt.Errorf("bad ID: %s", id)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testAccConsulKeyPrefixConfig = `
+resource "consulclient_key_prefix" "app" {
+	path_prefix = "prefix_test/"
+
+	subkeys = {
+		cheese = "chevre"
+		bread  = "baguette"
+	}
+}
+`
+
+const testAccConsulKeyPrefixConfig_update = `
+resource "consulclient_key_prefix" "app" {
+	path_prefix = "prefix_test/"
+
+	subkeys = {
+		meat  = "ham"
+		bread = "batard"
+	}
+}
+`
+
+const testAccConsulKeyPrefixConfig_host = `
+resource "consulclient_key_prefix" "app" {
+	host        = "%s"
+	scheme      = "http"
+	datacenter  = "dc2"
+	path_prefix = "prefix_test/"
+
+	subkeys = {
+		cheese = "chevre"
+	}
+}
+`
+
+const testAccConsulKeyPrefixConfig_lock = `
+resource "consulclient_session" "lock" {
+	name = "prefix-lock"
+}
+
+resource "consulclient_key_prefix" "app" {
+	path_prefix = "prefix_test/"
+	cas         = true
+
+	lock {
+		key     = "locks/prefix"
+		session = "${consulclient_session.lock.id}"
+	}
+
+	subkeys = {
+		cheese = "chevre"
+		bread  = "baguette"
+	}
+}
+`
+
+func testAccConsulKeyPrefixConfig_manyKeys(count int, value string) string {
+	var subkeys []string
+	for i := 0; i < count; i++ {
+		subkeys = append(subkeys, fmt.Sprintf("\t\tkey%02d = \"%s\"", i, value))
+	}
+	return fmt.Sprintf(`
+resource "consulclient_key_prefix" "app" {
+	path_prefix = "prefix_test/"
+	cas         = true
+
+	subkeys = {
+%s
+	}
+}
+`, strings.Join(subkeys, "\n"))
+}
+
+const testAccConsulKeyPrefixConfig_flags = `
+resource "consulclient_key_prefix" "app" {
+	path_prefix = "prefix_test/"
+
+	subkeys = {
+		cheese = "chevre"
+		bread  = "baguette"
+	}
+
+	subkey_flags = {
+		cheese = "%s"
+	}
+}
+`
+
+const testAccConsulKeyPrefixConfig_flagsWithoutValue = `
+resource "consulclient_key_prefix" "app" {
+	path_prefix = "prefix_test/"
+
+	subkeys = {
+		cheese = "chevre"
+	}
+
+	subkey_flags = {
+		wine = "1"
+	}
+}
+`
+
+const testAccConsulKeyPrefixConfig_partial = `
+resource "consulclient_key_prefix" "app" {
+	path_prefix    = "prefix_test/"
+	ownership      = "partial"
+	adopt_existing = %t
+
+	subkeys = {
+		cheese = "chevre"
+		bread  = "baguette"
+	}
+}
+`
+
+const testAccConsulKeyPrefixConfig_partialUpdate = `
+resource "consulclient_key_prefix" "app" {
+	path_prefix = "prefix_test/"
+	ownership   = "partial"
+
+	subkeys = {
+		cheese = "chevre"
+		meat   = "ham"
+	}
+}
+`
+
+const testAccConsulKeyPrefixConfig_partialForeign = `
+resource "consulclient_key_prefix" "app" {
+	path_prefix = "prefix_test/"
+	ownership   = "partial"
+
+	subkeys = {
+		cheese  = "chevre"
+		meat    = "ham"
+		foreign = "app"
+	}
+}
+`
+
+const testAccConsulKeyPrefixConfig_purge = `
+resource "consulclient_key_prefix" "app" {
+	path_prefix     = "prefix_test/"
+	purge_unmanaged = true
+	preserve        = ["keep/*"]
+
+	subkeys = {
+		cheese = "chevre"
+	}
+}
+`
+
+const testAccConsulKeyPrefixConfig_sensitive = `
+resource "consulclient_key_prefix" "app" {
+	path_prefix = "prefix_test/"
+
+	subkeys = {
+		user = "%s"
+	}
+
+	sensitive_subkeys = {
+		password = "%s"
+	}
+}
+`
+
+const testAccConsulKeyPrefixConfig_sensitiveRemoved = `
+resource "consulclient_key_prefix" "app" {
+	path_prefix = "prefix_test/"
+
+	subkeys = {
+		user = "admin"
+	}
+}
+`
+
+const testAccConsulKeyPrefixConfig_sensitiveBase64 = `
+resource "consulclient_key_prefix" "app" {
+	path_prefix = "prefix_test/"
+	ownership   = "partial"
+
+	sensitive_subkeys_base64 = {
+		cert = "%s"
+	}
+}
+`
+
+const testAccConsulKeyPrefixConfig_base64 = `
+resource "consulclient_key_prefix" "app" {
+	path_prefix = "prefix_test/"
+
+	subkeys = {
+		cheese = "chevre"
+	}
+
+	subkeys_base64 = {
+		blob = "%s"
+	}
+}
+`
+
+// testAccCheckConsulKeyPrefixRefreshedSubKeys refreshes the resource n and
+// checks the subkeys it reads.
+func testAccCheckConsulKeyPrefixRefreshedSubKeys(n string, keys ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+		state, err := testAccProvider.ResourcesMap[rs.Type].Refresh(rs.Primary, testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+		if got := state.Attributes["subkeys.%"]; got != fmt.Sprint(len(keys)) {
+			return fmt.Errorf("%s reads %s subkeys; want %d", n, got, len(keys))
+		}
+		for _, k := range keys {
+			if _, ok := state.Attributes["subkeys."+k]; !ok {
+				return fmt.Errorf("%s does not read subkey '%s'", n, k)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccConsulKeyPrefixApply applies diff to the resource n, without
+// refreshing it first.
+func testAccConsulKeyPrefixApply(n string, diff *terraform.InstanceDiff) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+		_, err := testAccProvider.ResourcesMap[rs.Type].Apply(rs.Primary, diff, testAccProvider.Meta())
+		return err
+	}
+}
@@ -0,0 +1,370 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceConsulKeyTree() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulKeyTreeCreate,
+		Update: resourceConsulKeyTreeUpdate,
+		Read:   resourceConsulKeyTreeRead,
+		Delete: resourceConsulKeyTreeDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulKeyTreeImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			"path_prefix": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+
+			// document is the tree of values stored under path_prefix, as
+			// an object in the given format. It cannot hold empty objects,
+			// nor, with the index array handling, empty arrays or objects
+			// whose keys are array indexes, nor, with the json one, strings
+			// holding a JSON array. These are rejected on apply.
+			"document": {
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressEquivalentKeyTreeDocuments,
+			},
+
+			"format": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  keyTreeFormatJSON,
+				ValidateFunc: makeValidationFunc("format", []interface{}{
+					validateRegexp(`^(json|yaml|hcl)$`),
+				}),
+			},
+
+			"separator": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "/",
+				ValidateFunc: makeValidationFunc("separator", []interface{}{
+					validateRegexp(`^.+$`),
+				}),
+			},
+
+			// array_handling is either "index", to store the elements of
+			// arrays under their index, or "json", to store arrays
+			// JSON-encoded in a single key.
+			"array_handling": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  keyTreeArraysIndex,
+				ValidateFunc: makeValidationFunc("array_handling", []interface{}{
+					validateRegexp(`^(index|json)$`),
+				}),
+			},
+
+			"lock": kvLockSchema(),
+
+			"cas": kvCASSchema(),
+
+			"modify_indexes": kvModifyIndexesSchema(),
+		},
+	}
+}
+
+func resourceConsulKeyTreeCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	kv := client.KV()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	keyClient := newKeyClient(kv, dc, token)
+	keyClient.cas = d.Get("cas").(bool)
+	keyClient.indexes = expandModifyIndexes(d)
+
+	unlock, err := acquireKVLock(client, d)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	pathPrefix := d.Get("path_prefix").(string)
+	values, err := keyTreeWriteValues(d.Get("document").(string), keyTreeOptionsFromResourceData(d))
+	if err != nil {
+		return err
+	}
+
+	// As for consulclient_key_prefix, we only "create" a tree under a
+	// prefix that is currently empty.
+	currentValues, err := keyClient.GetUnderPrefix(pathPrefix)
+	if err != nil {
+		return err
+	}
+	if len(currentValues) > 0 {
+		return fmt.Errorf(
+			"%d keys already exist under %s; delete them before managing this prefix with Terraform",
+			len(currentValues), pathPrefix,
+		)
+	}
+
+	// The resource is recorded as created before anything is written, so
+	// that a partial write is recovered from by an Update.
+	d.SetId(pathPrefix)
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	txn := keyClient.NewTxn()
+	for _, k := range sortedStringKeys(values) {
+		txn.Set(pathPrefix+k, values[k], 0)
+	}
+	if err := txn.Commit(); err != nil {
+		return fmt.Errorf("error while writing %s: %s", pathPrefix, err)
+	}
+
+	return resourceConsulKeyTreeRead(d, meta)
+}
+
+func resourceConsulKeyTreeUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	kv := client.KV()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	keyClient := newKeyClient(kv, dc, token)
+	keyClient.cas = d.Get("cas").(bool)
+	keyClient.indexes = expandModifyIndexes(d)
+
+	unlock, err := acquireKVLock(client, d)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	pathPrefix := d.Id()
+
+	if d.HasChange("document") || d.HasChange("format") || d.HasChange("separator") || d.HasChange("array_handling") {
+		var oldOpts, newOpts keyTreeOptions
+		o, n := d.GetChange("format")
+		oldOpts.Format, newOpts.Format = o.(string), n.(string)
+		o, n = d.GetChange("separator")
+		oldOpts.Separator, newOpts.Separator = o.(string), n.(string)
+		o, n = d.GetChange("array_handling")
+		oldOpts.Arrays, newOpts.Arrays = o.(string), n.(string)
+
+		o, n = d.GetChange("document")
+		om, err := keyTreeValues(o.(string), oldOpts)
+		if err != nil {
+			return err
+		}
+		nm, err := keyTreeWriteValues(n.(string), newOpts)
+		if err != nil {
+			// Nothing was written, so the state is kept as it was.
+			d.Partial(true)
+			return err
+		}
+
+		// As for consulclient_key_prefix, the changes are written in a
+		// single transaction, which in CAS mode also checks the
+		// unchanged keys.
+		txn := keyClient.NewTxn()
+		for _, k := range sortedStringKeys(nm) {
+			if old, exists := om[k]; exists && old == nm[k] {
+				txn.Check(pathPrefix + k)
+				continue
+			}
+			txn.Set(pathPrefix+k, nm[k], 0)
+		}
+		for _, k := range sortedStringKeys(om) {
+			if _, exists := nm[k]; exists {
+				continue
+			}
+			txn.Delete(pathPrefix + k)
+		}
+		if err := txn.Commit(); err != nil {
+			return fmt.Errorf("error while writing %s: %s", pathPrefix, err)
+		}
+	}
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	return resourceConsulKeyTreeRead(d, meta)
+}
+
+func resourceConsulKeyTreeRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	kv := client.KV()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	keyClient := newKeyClient(kv, dc, token)
+
+	pathPrefix := d.Id()
+	opts := keyTreeOptionsFromResourceData(d)
+
+	values, err := keyClient.GetUnderPrefix(pathPrefix)
+	if err != nil {
+		return err
+	}
+
+	// The document is kept as it was written as long as the keys still
+	// hold its values, so that its formatting and the types of its values
+	// are preserved. Otherwise it is rebuilt from the keys, with all the
+	// values as strings, so that the drift shows up in the diff.
+	current, err := keyTreeValues(d.Get("document").(string), opts)
+	if err != nil || !equalKeyTreeValues(current, values) {
+		if err == nil {
+			log.Printf("[DEBUG] Keys under '%s' differ from the document; rebuilding it", pathPrefix)
+		}
+		doc, err := encodeKeyTreeDocument(expandKeyTree(values, opts), opts)
+		if err != nil {
+			return err
+		}
+		d.Set("document", doc)
+	}
+
+	d.Set("path_prefix", pathPrefix)
+	d.Set("modify_indexes", flattenModifyIndexes(keyClient.indexes))
+
+	// Store the datacenter on this resource, which can be helpful for reference
+	// in case it was read from the provider
+	d.Set("datacenter", dc)
+
+	return nil
+}
+
+func resourceConsulKeyTreeDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+	kv := client.KV()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+
+	keyClient := newKeyClient(kv, dc, token)
+	keyClient.cas = d.Get("cas").(bool)
+	keyClient.indexes = expandModifyIndexes(d)
+
+	unlock, err := acquireKVLock(client, d)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	pathPrefix := d.Id()
+
+	// Like consulclient_key_prefix, the entire prefix is considered to be
+	// managed exclusively by Terraform.
+	err = keyClient.DeleteUnderPrefix(pathPrefix)
+	if err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// resourceConsulKeyTreeImport imports the document stored under the path
+// prefix given as ID. It is read in the default format, with the default
+// separator and array handling.
+func resourceConsulKeyTreeImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := parseImportTarget(d); err != nil {
+		return nil, err
+	}
+	if err := setImportDefaults(d, resourceConsulKeyTree().Schema); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,318 @@
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccConsulKeyTree_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul,
+			"tree_test/service/name", "tree_test/service/port", "tree_test/service/tags/0", "tree_test/service/tags/1",
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulKeyTreeConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "tree_test/service/name", "web"),
+					testAccCheckConsulKeysValue(consul, "tree_test/service/port", "8080"),
+					testAccCheckConsulKeysValue(consul, "tree_test/service/tags/0", "a"),
+					testAccCheckConsulKeysValue(consul, "tree_test/service/tags/1", "b"),
+					testAccCheckConsulKeysValue(consul, "tree_test/enabled", "true"),
+					resource.TestCheckResourceAttr("consulclient_key_tree.app", "modify_indexes.%", "5"),
+				),
+			},
+			{
+				// The same values with another layout are not a change.
+				Config:   testAccProviderConfig(consul) + testAccConsulKeyTreeConfig_reordered,
+				PlanOnly: true,
+			},
+			{
+				// Drift in Consul shows up as a diff.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.kv["tree_test/service/port"].Value = []byte("9090")
+				},
+				Config:             testAccProviderConfig(consul) + testAccConsulKeyTreeConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulKeyTreeConfig_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "tree_test/service/name", "api"),
+					testAccCheckConsulKeysValue(consul, "tree_test/service/port", "8080"),
+					testAccCheckConsulKeysValue(consul, "tree_test/service/tags/0", "c"),
+					testAccCheckConsulKeysRemoved(consul, "tree_test/service/tags/1", "tree_test/enabled"),
+				),
+			},
+			{
+				// The imported document is rebuilt from the keys, with
+				// all the values as strings, and cas is only set in the
+				// configuration.
+				ResourceName:            "consulclient_key_tree.app",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"document", "cas"},
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyTree_yaml(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul, "tree_test/service.name", "tree_test/service.tags"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyTreeConfig_yaml, "web"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "tree_test/service.name", "web"),
+					testAccCheckConsulKeysValue(consul, "tree_test/service.tags", `["a","b"]`),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyTreeConfig_yaml, "api"),
+				Check:  testAccCheckConsulKeysValue(consul, "tree_test/service.name", "api"),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeyTree_format(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul, "tree_test/ratio"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyTreeConfig_format, "json"),
+				Check:  testAccCheckConsulKeysValue(consul, "tree_test/ratio", "1.0"),
+			},
+			{
+				// The same document is stored differently in another
+				// format, which is written even though it is unchanged.
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyTreeConfig_format, "yaml"),
+				Check:  testAccCheckConsulKeysValue(consul, "tree_test/ratio", "1"),
+			},
+			{
+				// Documents that would not be read back as written are
+				// rejected.
+				Config:      testAccProviderConfig(consul) + testAccConsulKeyTreeConfig_emptyObject,
+				ExpectError: regexp.MustCompile("The object at 'service' is empty; empty objects cannot be stored"),
+			},
+			{
+				Config:   testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeyTreeConfig_format, "yaml"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestKeyTree(t *testing.T) {
+	cases := []struct {
+		doc    string
+		opts   keyTreeOptions
+		values map[string]string
+		expand string
+	}{
+		{
+			doc:  `{"a": {"b": "c", "n": 1.5, "l": [1, {"d": true}]}, "z": null}`,
+			opts: keyTreeOptions{Format: keyTreeFormatJSON, Separator: "/", Arrays: keyTreeArraysIndex},
+			values: map[string]string{
+				"a/b": "c", "a/n": "1.5", "a/l/0": "1", "a/l/1/d": "true", "z": "",
+			},
+			expand: "{\n  \"a\": {\n    \"b\": \"c\",\n    \"l\": [\n      \"1\",\n      {\n        \"d\": \"true\"\n      }\n    ],\n    \"n\": \"1.5\"\n  },\n  \"z\": \"\"\n}\n",
+		},
+		{
+			doc:  "a:\n  b: c\n  l: [1, 2]\n",
+			opts: keyTreeOptions{Format: keyTreeFormatYAML, Separator: ".", Arrays: keyTreeArraysJSON},
+			values: map[string]string{
+				"a.b": "c", "a.l": "[1,2]",
+			},
+			expand: "a:\n  b: c\n  l:\n  - 1\n  - 2\n",
+		},
+		{
+			doc:  "a { b = \"c\" }\nn = 3\n",
+			opts: keyTreeOptions{Format: keyTreeFormatHCL, Separator: "/", Arrays: keyTreeArraysIndex},
+			values: map[string]string{
+				"a/b": "c", "n": "3",
+			},
+			expand: "{\n  \"a\": {\n    \"b\": \"c\"\n  },\n  \"n\": \"3\"\n}\n",
+		},
+	}
+
+	for i, tc := range cases {
+		values, err := keyTreeValues(tc.doc, tc.opts)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(values, tc.values) {
+			t.Fatalf("%d: bad values: %#v", i, values)
+		}
+
+		doc, err := encodeKeyTreeDocument(expandKeyTree(values, tc.opts), tc.opts)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		if doc != tc.expand {
+			t.Fatalf("%d: bad document: %q", i, doc)
+		}
+
+		// The rebuilt document is stored as the same keys.
+		rebuilt, err := keyTreeValues(doc, tc.opts)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(rebuilt, tc.values) {
+			t.Fatalf("%d: bad values of the rebuilt document: %#v", i, rebuilt)
+		}
+	}
+
+	if _, err := keyTreeValues(`{"a/b": "c"}`, keyTreeOptions{Format: keyTreeFormatJSON, Separator: "/"}); err == nil {
+		t.Fatalf("expected an error for a key containing the separator")
+	}
+	if _, err := keyTreeValues(`["a"]`, keyTreeOptions{Format: keyTreeFormatJSON, Separator: "/"}); err == nil {
+		t.Fatalf("expected an error for a document that is not an object")
+	}
+}
+
+func TestValidateKeyTreeDocument(t *testing.T) {
+	index := keyTreeOptions{Format: keyTreeFormatJSON, Separator: "/", Arrays: keyTreeArraysIndex}
+	arrays := keyTreeOptions{Format: keyTreeFormatJSON, Separator: "/", Arrays: keyTreeArraysJSON}
+	yamlIndex := keyTreeOptions{Format: keyTreeFormatYAML, Separator: ".", Arrays: keyTreeArraysIndex}
+	hclIndex := keyTreeOptions{Format: keyTreeFormatHCL, Separator: "/", Arrays: keyTreeArraysIndex}
+
+	cases := []struct {
+		doc   string
+		opts  keyTreeOptions
+		valid bool
+	}{
+		{`{}`, index, true},
+		{`{"a": {"b": [1, {"c": "d"}]}, "0": "e"}`, index, true},
+		{"a:\n  b: c\n", yamlIndex, true},
+		{"a { b = \"c\" }\n", hclIndex, true},
+		{`{"a": {}}`, index, false},
+		{`{"a": {}}`, arrays, false},
+		{`{"a": {"b": []}}`, index, false},
+		{`{"a": {"0": "b", "1": "c"}}`, index, false},
+		{`{"0": "a"}`, index, false},
+		{"a:\n  b: []\n", yamlIndex, false},
+		{"a {}\n", hclIndex, false},
+		// JSON-encoded arrays are read back as they are, and objects
+		// are not read back as arrays.
+		{`{"a": {"b": [], "c": [{}]}}`, arrays, true},
+		{`{"a": {"0": "b", "1": "c"}}`, arrays, true},
+		// Strings holding a JSON array are only read back as arrays when
+		// arrays are JSON-encoded.
+		{`{"a": "[1, 2]"}`, index, true},
+		{`{"a": "[1, 2]"}`, arrays, false},
+		{`{"a": "[not an array"}`, arrays, true},
+	}
+
+	for i, tc := range cases {
+		doc, err := decodeKeyTreeDocument(tc.doc, tc.opts)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		err = validateKeyTreeDocument(doc, tc.opts)
+		if tc.valid && err != nil {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		if !tc.valid && err == nil {
+			t.Fatalf("%d: expected an error for %q", i, tc.doc)
+		}
+	}
+}
+
+const testAccConsulKeyTreeConfig = `
+resource "consulclient_key_tree" "app" {
+	path_prefix = "tree_test/"
+
+	document = <<EOF
+{
+  "service": {
+    "name": "web",
+    "port": 8080,
+    "tags": ["a", "b"]
+  },
+  "enabled": true
+}
+EOF
+}
+`
+
+const testAccConsulKeyTreeConfig_reordered = `
+resource "consulclient_key_tree" "app" {
+	path_prefix = "tree_test/"
+
+	document = <<EOF
+{"enabled": "true", "service": {"tags": ["a", "b"], "port": "8080", "name": "web"}}
+EOF
+}
+`
+
+const testAccConsulKeyTreeConfig_update = `
+resource "consulclient_key_tree" "app" {
+	path_prefix = "tree_test/"
+	cas         = true
+
+	document = <<EOF
+{
+  "service": {
+    "name": "api",
+    "port": 8080,
+    "tags": ["c"]
+  }
+}
+EOF
+}
+`
+
+const testAccConsulKeyTreeConfig_yaml = `
+resource "consulclient_key_tree" "app" {
+	path_prefix    = "tree_test/"
+	format         = "yaml"
+	separator      = "."
+	array_handling = "json"
+
+	document = <<EOF
+service:
+  name: %s
+  tags:
+    - a
+    - b
+EOF
+}
+`
+
+const testAccConsulKeyTreeConfig_format = `
+resource "consulclient_key_tree" "app" {
+	path_prefix = "tree_test/"
+	format      = "%s"
+	document    = "{\"ratio\": 1.0}"
+}
+`
+
+const testAccConsulKeyTreeConfig_emptyObject = `
+resource "consulclient_key_tree" "app" {
+	path_prefix = "tree_test/"
+	format      = "yaml"
+	document    = "{\"ratio\": 1.0, \"service\": {}}"
+}
+`
@@ -1,10 +1,13 @@
 package provider
 
 import (
+	"bytes"
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
-	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/hashcode"
 	"github.com/hashicorp/terraform/helper/schema"
 )
 
@@ -15,6 +18,10 @@ func resourceConsulKeys() *schema.Resource {
 		Read:   resourceConsulKeysRead,
 		Delete: resourceConsulKeysDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulKeysImport,
+		},
+
 		SchemaVersion: 1,
 		MigrateState:  resourceConsulKeysMigrateState,
 
@@ -58,14 +65,14 @@ func resourceConsulKeys() *schema.Resource {
 				ForceNew: true,
 			},
 
-			"token": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
 
 			"key": {
 				Type:     schema.TypeSet,
 				Optional: true,
+				Set:      resourceConsulKeysKeyHash,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"name": {
@@ -85,6 +92,46 @@ func resourceConsulKeys() *schema.Resource {
 							Computed: true,
 						},
 
+						// value_base64 is used instead of value to write
+						// values that are not UTF-8 text.
+						"value_base64": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+
+						// sensitive_value and sensitive_value_base64 are
+						// used instead of value and value_base64 to write
+						// the value of a sensitive key, which plans do not
+						// show.
+						"sensitive_value": {
+							Type:      schema.TypeString,
+							Optional:  true,
+							Sensitive: true,
+						},
+
+						"sensitive_value_base64": {
+							Type:      schema.TypeString,
+							Optional:  true,
+							Sensitive: true,
+						},
+
+						// value_hash holds the hash of the value of a
+						// sensitive key, which is stored instead of its
+						// value.
+						"value_hash": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"flags": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  0,
+							ValidateFunc: makeValidationFunc("flags", []interface{}{
+								validateIntMin(0),
+							}),
+						},
+
 						"default": {
 							Type:     schema.TypeString,
 							Optional: true,
@@ -95,6 +142,17 @@ func resourceConsulKeys() *schema.Resource {
 							Optional: true,
 							Default:  false,
 						},
+
+						// sensitive keeps the value of the key out of the
+						// state and of var, where only its hash is stored,
+						// in value_hash and var. The keys written from
+						// sensitive_value or sensitive_value_base64 are
+						// sensitive whether or not it is set.
+						"sensitive": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
 					},
 				},
 			},
@@ -103,6 +161,12 @@ func resourceConsulKeys() *schema.Resource {
 				Type:     schema.TypeMap,
 				Computed: true,
 			},
+
+			"lock": kvLockSchema(),
+
+			"cas": kvCASSchema(),
+
+			"modify_indexes": kvModifyIndexesSchema(),
 		},
 	}
 }
@@ -118,14 +182,23 @@ func resourceConsulKeysCreate(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 	kv := client.KV()
-	token := d.Get("token").(string)
-	dc, err := getDC(d, client)
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
 
 	keyClient := newKeyClient(kv, dc, token)
+	keyClient.cas = d.Get("cas").(bool)
+	keyClient.indexes = expandModifyIndexes(d)
+
+	unlock, err := acquireKVLock(client, d)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
+	txn := keyClient.NewTxn()
 	keys := d.Get("key").(*schema.Set).List()
 	for _, raw := range keys {
 		_, path, sub, err := parseKey(raw)
@@ -133,14 +206,18 @@ func resourceConsulKeysCreate(d *schema.ResourceData, meta interface{}) error {
 			return err
 		}
 
-		value := sub["value"].(string)
+		value, err := keyWriteValue(path, sub)
+		if err != nil {
+			return err
+		}
 		if value == "" {
 			continue
 		}
 
-		if err := keyClient.Put(path, value); err != nil {
-			return err
-		}
+		txn.Set(path, value, uint64(sub["flags"].(int)))
+	}
+	if err := txn.Commit(); err != nil {
+		return err
 	}
 
 	// The ID doesn't matter, since we use provider config, datacenter,
@@ -162,13 +239,21 @@ func resourceConsulKeysUpdate(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 	kv := client.KV()
-	token := d.Get("token").(string)
-	dc, err := getDC(d, client)
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
 
 	keyClient := newKeyClient(kv, dc, token)
+	keyClient.cas = d.Get("cas").(bool)
+	keyClient.indexes = expandModifyIndexes(d)
+
+	unlock, err := acquireKVLock(client, d)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	if d.HasChange("key") {
 		o, n := d.GetChange("key")
@@ -191,6 +276,10 @@ func resourceConsulKeysUpdate(d *schema.ResourceData, meta interface{}) error {
 		// value and then immediately removing it.
 		addedPaths := make(map[string]bool)
 
+		// All the writes are applied in a single transaction, so that
+		// the keys are updated all at once or not at all.
+		txn := keyClient.NewTxn()
+
 		// We add before we remove because then it's possible to change
 		// a key name (which will result in both an add and a remove)
 		// without very temporarily having *neither* value in the store.
@@ -202,14 +291,15 @@ func resourceConsulKeysUpdate(d *schema.ResourceData, meta interface{}) error {
 				return err
 			}
 
-			value := sub["value"].(string)
+			value, err := keyWriteValue(path, sub)
+			if err != nil {
+				return err
+			}
 			if value == "" {
 				continue
 			}
 
-			if err := keyClient.Put(path, value); err != nil {
-				return err
-			}
+			txn.Set(path, value, uint64(sub["flags"].(int)))
 			addedPaths[path] = true
 		}
 
@@ -230,9 +320,11 @@ func resourceConsulKeysUpdate(d *schema.ResourceData, meta interface{}) error {
 				continue
 			}
 
-			if err := keyClient.Delete(path); err != nil {
-				return err
-			}
+			txn.Delete(path)
+		}
+
+		if err := txn.Commit(); err != nil {
+			return err
 		}
 	}
 
@@ -254,8 +346,8 @@ func resourceConsulKeysRead(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 	kv := client.KV()
-	token := d.Get("token").(string)
-	dc, err := getDC(d, client)
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
@@ -277,11 +369,15 @@ func resourceConsulKeysRead(d *schema.ResourceData, meta interface{}) error {
 		}
 
 		value = attributeValue(sub, value)
+		sensitive := isSensitiveKey(sub)
 		if key != "" {
 			// If key is set then we'll update vars, for backward-compatibilty
 			// with the pre-0.7 capability to read from Consul with this
 			// resource.
 			vars[key] = value
+			if sensitive {
+				vars[key] = sensitiveValueHash(value)
+			}
 		}
 
 		// If there is already a "value" attribute present for this key
@@ -291,8 +387,25 @@ func resourceConsulKeysRead(d *schema.ResourceData, meta interface{}) error {
 		// recently written by Terraform.
 		// We don't do this for "read" blocks; that causes confusing diffs
 		// because "value" should not be set for read-only key blocks.
-		if oldValue := sub["value"]; oldValue != "" {
-			sub["value"] = value
+		write := sub["value"] != "" || sub["value_base64"] != "" || sub["value_hash"] != "" ||
+			sub["sensitive_value"] != "" || sub["sensitive_value_base64"] != ""
+		if sensitive && write {
+			sub["value"] = ""
+			sub["value_base64"] = ""
+			sub["sensitive_value"] = ""
+			sub["sensitive_value_base64"] = ""
+			sub["value_hash"] = sensitiveValueHash(value)
+		} else {
+			if oldValue := sub["value"]; oldValue != "" {
+				sub["value"] = value
+			}
+			if oldValue := sub["value_base64"]; oldValue != "" {
+				sub["value_base64"] = encodeBase64Value(value)
+			}
+			sub["value_hash"] = ""
+		}
+		if write {
+			sub["flags"] = int(keyClient.flags[path])
 		}
 	}
 
@@ -302,6 +415,9 @@ func resourceConsulKeysRead(d *schema.ResourceData, meta interface{}) error {
 	if err := d.Set("key", keys); err != nil {
 		return err
 	}
+	if err := d.Set("modify_indexes", flattenModifyIndexes(keyClient.indexes)); err != nil {
+		return err
+	}
 
 	// Store the datacenter on this resource, which can be helpful for reference
 	// in case it was read from the provider
@@ -321,15 +437,24 @@ func resourceConsulKeysDelete(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 	kv := client.KV()
-	token := d.Get("token").(string)
-	dc, err := getDC(d, client)
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
 	if err != nil {
 		return err
 	}
 
 	keyClient := newKeyClient(kv, dc, token)
+	keyClient.cas = d.Get("cas").(bool)
+	keyClient.indexes = expandModifyIndexes(d)
+
+	unlock, err := acquireKVLock(client, d)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	// Clean up any keys that we're explicitly managing
+	txn := keyClient.NewTxn()
 	keys := d.Get("key").(*schema.Set).List()
 	for _, raw := range keys {
 		_, path, sub, err := parseKey(raw)
@@ -343,9 +468,10 @@ func resourceConsulKeysDelete(d *schema.ResourceData, meta interface{}) error {
 			continue
 		}
 
-		if err := keyClient.Delete(path); err != nil {
-			return err
-		}
+		txn.Delete(path)
+	}
+	if err := txn.Commit(); err != nil {
+		return err
 	}
 
 	// Clear the ID
@@ -353,6 +479,72 @@ func resourceConsulKeysDelete(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
+// resourceConsulKeysImport imports the comma-separated list of key paths
+// given as ID, e.g. "app/config/port,app/config/host".
+func resourceConsulKeysImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := parseImportTarget(d); err != nil {
+		return nil, err
+	}
+
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return nil, err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	kv := client.KV()
+	token := resolvedConfig.Token
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return nil, err
+	}
+
+	keyClient := newKeyClient(kv, dc, token)
+
+	var keys []interface{}
+	for _, path := range strings.Split(d.Id(), ",") {
+		if path == "" {
+			continue
+		}
+
+		value, err := keyClient.Get(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := keyClient.indexes[path]; !ok {
+			return nil, fmt.Errorf("Consul key '%s' does not exist", path)
+		}
+
+		key := map[string]interface{}{
+			"path":  path,
+			"value": value,
+			"flags": int(keyClient.flags[path]),
+		}
+		if !utf8.ValidString(value) {
+			key["value"] = ""
+			key["value_base64"] = encodeBase64Value(value)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("Invalid import ID %q: expected a comma-separated list of key paths", d.Id())
+	}
+
+	if err := d.Set("key", keys); err != nil {
+		return nil, err
+	}
+	d.Set("datacenter", dc)
+	if err := setImportDefaults(d, resourceConsulKeys().Schema); err != nil {
+		return nil, err
+	}
+	d.SetId("consul")
+
+	return []*schema.ResourceData{d}, nil
+}
+
 // parseKey is used to parse a key into a name, path, config or error
 func parseKey(raw interface{}) (string, string, map[string]interface{}, error) {
 	sub, ok := raw.(map[string]interface{})
@@ -369,6 +561,106 @@ func parseKey(raw interface{}) (string, string, map[string]interface{}, error) {
 	return key, path, sub, nil
 }
 
+// keyValueAttributes are the attributes of a key block that hold the value
+// to write, at most one of which can be set.
+var keyValueAttributes = []string{"value", "value_base64", "sensitive_value", "sensitive_value_base64"}
+
+// keyWriteValue returns the value to write to the key path described by
+// sub, which is empty for keys that are only read.
+func keyWriteValue(path string, sub map[string]interface{}) (string, error) {
+	attr := ""
+	for _, k := range keyValueAttributes {
+		if s, _ := sub[k].(string); s != "" {
+			if attr != "" {
+				return "", fmt.Errorf("Failed to write Consul key '%s': only one of %s can be set", path, strings.Join(keyValueAttributes, ", "))
+			}
+			attr = k
+		}
+	}
+	if attr == "" {
+		return "", nil
+	}
+	if sensitive, _ := sub["sensitive"].(bool); sensitive && !strings.HasPrefix(attr, "sensitive_") {
+		return "", fmt.Errorf("Failed to write Consul key '%s': plans show %s, so the value of a sensitive key must be set in sensitive_value or sensitive_value_base64", path, attr)
+	}
+
+	value := sub[attr].(string)
+	if !strings.HasSuffix(attr, "_base64") {
+		return value, nil
+	}
+	value, err := decodeBase64Value(value)
+	if err != nil {
+		return "", fmt.Errorf("Failed to write Consul key '%s': %v", path, err)
+	}
+	return value, nil
+}
+
+// resourceConsulKeysKeyHash hashes the key blocks like schema.HashResource
+// would, except that base64 values are hashed by the bytes they encode, so
+// that equivalent encodings do not show up as a diff.
+func resourceConsulKeysKeyHash(v interface{}) int {
+	var buf bytes.Buffer
+	m := v.(map[string]interface{})
+	sensitive := isSensitiveKey(m)
+	keys := []string{"default", "name", "path", "value"}
+	if sensitive {
+		keys = keys[:3]
+	}
+	for _, k := range keys {
+		if s, ok := m[k].(string); ok {
+			buf.WriteString(fmt.Sprintf("%s:%s;", k, s))
+		}
+	}
+	if sensitive {
+		// The value of a sensitive key is hashed the same in the
+		// configuration, where it is in sensitive_value or
+		// sensitive_value_base64, and in the state, where only its hash
+		// is.
+		buf.WriteString("sensitive:true;")
+		if h := sensitiveKeyValueHash(m); h != "" {
+			buf.WriteString(fmt.Sprintf("value_hash:%s;", h))
+		}
+	}
+	if d, ok := m["delete"].(bool); ok {
+		buf.WriteString(fmt.Sprintf("delete:%t;", d))
+	}
+	if f, ok := m["flags"].(int); ok && f != 0 {
+		buf.WriteString(fmt.Sprintf("flags:%d;", f))
+	}
+	if s, ok := m["value_base64"].(string); ok && s != "" && !sensitive {
+		buf.WriteString(fmt.Sprintf("value_base64:%s;", base64ContentHash(s)))
+	}
+	return hashcode.String(buf.String())
+}
+
+// isSensitiveKey returns whether the key block m is sensitive: flagged so,
+// or holding a sensitive value, as configured or as hashed in state.
+func isSensitiveKey(m map[string]interface{}) bool {
+	if sensitive, _ := m["sensitive"].(bool); sensitive {
+		return true
+	}
+	for _, k := range []string{"sensitive_value", "sensitive_value_base64", "value_hash"} {
+		if s, _ := m[k].(string); s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// sensitiveKeyValueHash returns the hash of the value of the sensitive key
+// block m, computed from its sensitive_value or sensitive_value_base64 when
+// configured, or else as stored in its value_hash.
+func sensitiveKeyValueHash(m map[string]interface{}) string {
+	if s, _ := m["sensitive_value"].(string); s != "" {
+		return sensitiveValueHash(s)
+	}
+	if s, _ := m["sensitive_value_base64"].(string); s != "" {
+		return sensitiveBase64Hash(s)
+	}
+	s, _ := m["value_hash"].(string)
+	return s
+}
+
 // attributeValue determines the value for a key, potentially
 // using a default value if provided.
 func attributeValue(sub map[string]interface{}, readValue string) string {
@@ -390,15 +682,3 @@ func attributeValue(sub map[string]interface{}, readValue string) string {
 	// No value
 	return ""
 }
-
-// getDC is used to get the datacenter of the local agent
-func getDC(d *schema.ResourceData, client *consulapi.Client) (string, error) {
-	if v, ok := d.GetOk("datacenter"); ok {
-		return v.(string), nil
-	}
-	info, err := client.Agent().Self()
-	if err != nil {
-		return "", fmt.Errorf("Failed to get datacenter from Consul agent: %v", err)
-	}
-	return info["Config"]["Datacenter"].(string), nil
-}
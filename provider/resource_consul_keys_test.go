@@ -0,0 +1,700 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulKeys_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul, "test/set", "test/other"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulKeysConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "test/set", "acceptance"),
+					testAccCheckConsulKeysValue(consul, "test/other", "value"),
+					resource.TestCheckResourceAttr("consulclient_keys.app", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("consulclient_keys.app", "key.#", "2"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulKeysConfig_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "test/set", "acceptanceUpdated"),
+					testAccCheckConsulKeysRemoved(consul, "test/other"),
+					resource.TestCheckResourceAttr("consulclient_keys.app", "key.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeys_import(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulKeysConfig_import,
+			},
+			{
+				ResourceName:      "consulclient_keys.app",
+				ImportState:       true,
+				ImportStateId:     "test/set,test/other",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulKeys_importEmpty(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	consul.kv["test/empty"] = &consulapi.KVPair{Key: "test/empty", ModifyIndex: 1}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				// A key that exists with an empty value is imported.
+				ResourceName:  "consulclient_keys.app",
+				ImportState:   true,
+				ImportStateId: "test/empty",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected 1 state, got %d", len(states))
+					}
+					for k, v := range states[0].Attributes {
+						if strings.HasSuffix(k, ".path") && v == "test/empty" {
+							return nil
+						}
+					}
+					return fmt.Errorf("Key 'test/empty' was not imported: %v", states[0].Attributes)
+				},
+			},
+			{
+				ResourceName:  "consulclient_keys.app",
+				ImportState:   true,
+				ImportStateId: "test/missing",
+				ExpectError:   regexp.MustCompile("Consul key 'test/missing' does not exist"),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeys_multipleEndpoints(t *testing.T) {
+	primary := newFakeConsul("dc1")
+	defer primary.Close()
+	secondary := newFakeConsul("dc2")
+	defer secondary.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckConsulKeysRemoved(primary, "test/primary"),
+			testAccCheckConsulKeysRemoved(secondary, "test/secondary"),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(primary) + fmt.Sprintf(testAccConsulKeysConfig_multipleEndpoints, secondary.Address()),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(primary, "test/primary", "one"),
+					testAccCheckConsulKeysRemoved(primary, "test/secondary"),
+					testAccCheckConsulKeysValue(secondary, "test/secondary", "two"),
+					testAccCheckConsulKeysRemoved(secondary, "test/primary"),
+					resource.TestCheckResourceAttr("consulclient_keys.primary", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("consulclient_keys.secondary", "datacenter", "dc2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeys_base64(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul, "test/binary"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_base64, "AAH/"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "test/binary", "\x00\x01\xff"),
+					resource.TestCheckResourceAttr("consulclient_keys.app", "key.#", "1"),
+				),
+			},
+			{
+				// An equivalent encoding is not a change.
+				Config:   testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_base64, "AAH/ "),
+				PlanOnly: true,
+			},
+			{
+				// Drift in Consul shows up as a diff.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.kv["test/binary"].Value = []byte{0xfe}
+				},
+				Config:             testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_base64, "AAH/"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_base64, "AAH/"),
+				Check:  testAccCheckConsulKeysValue(consul, "test/binary", "\x00\x01\xff"),
+			},
+			{
+				ResourceName:  "consulclient_keys.app",
+				ImportState:   true,
+				ImportStateId: "test/binary",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected 1 state, got %d", len(states))
+					}
+					for k, v := range states[0].Attributes {
+						if strings.HasSuffix(k, ".value_base64") && v == "AAH/" {
+							return nil
+						}
+					}
+					return fmt.Errorf("Key 'test/binary' was not imported as base64: %v", states[0].Attributes)
+				},
+			},
+		},
+	})
+}
+
+func TestAccConsulKeys_flags(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul, "test/flagged"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_flags, 42),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "test/flagged", "value"),
+					testAccCheckConsulKeysFlags(consul, "test/flagged", 42),
+				),
+			},
+			{
+				// Drift of the flags in Consul shows up as a diff.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.kv["test/flagged"].Flags = 7
+				},
+				Config:             testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_flags, 42),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_flags, 42),
+				Check:  testAccCheckConsulKeysFlags(consul, "test/flagged", 42),
+			},
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_flags, 0),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "test/flagged", "value"),
+					testAccCheckConsulKeysFlags(consul, "test/flagged", 0),
+				),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeys_lock(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul, "test/set"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_lock, "acceptance"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "test/set", "acceptance"),
+					testAccCheckConsulKeyLockReleased(consul, "locks/app"),
+					resource.TestCheckResourceAttr("consulclient_keys.app", "modify_indexes.%", "1"),
+				),
+			},
+			{
+				// Writes wait for the lock held by someone else.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.sessions["other"] = &consulapi.SessionEntry{ID: "other"}
+					consul.kv["locks/app"].Session = "other"
+				},
+				Config:      testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_lock, "updated"),
+				ExpectError: regexp.MustCompile("still held by another session"),
+			},
+			{
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.kv["locks/app"].Session = ""
+				},
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_lock, "updated"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "test/set", "updated"),
+					testAccCheckConsulKeyLockReleased(consul, "locks/app"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeys_casConflict(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	consul.kv["test/set"] = &consulapi.KVPair{Key: "test/set", Value: []byte("other"), ModifyIndex: 1}
+
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_lock, "acceptance"),
+				ExpectError: regexp.MustCompile("Conflict writing Consul key 'test/set': it was created since it was last read"),
+			},
+		},
+	})
+}
+
+func TestAccConsulKeys_sensitive(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulKeysRemoved(consul, "test/secret", "test/secret_binary", "test/digest"),
+		Steps: []resource.TestStep{
+			{
+				// Plans show value, so it cannot hold the value of a
+				// sensitive key.
+				Config:      testAccProviderConfig(consul) + testAccConsulKeysConfig_sensitivePlain,
+				ExpectError: regexp.MustCompile("the value of a sensitive key must be set in sensitive_value or sensitive_value_base64"),
+			},
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_sensitive, "hunter2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "test/secret", "hunter2"),
+					testAccCheckConsulKeysValue(consul, "test/secret_binary", "\x00\x01\xff"),
+					testAccCheckStateDoesNotContain("consulclient_keys.app", "hunter2", "AAH/"),
+					testAccCheckStateContains("consulclient_keys.app", sensitiveValueHash("hunter2")),
+					testAccCheckStateContains("consulclient_keys.app", sensitiveValueHash("\x00\x01\xff")),
+				),
+			},
+			{
+				// Drift in Consul shows up as a diff.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.kv["test/secret"].Value = []byte("hunter3")
+				},
+				Config:             testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_sensitive, "hunter2"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_sensitive, "correct horse"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "test/secret", "correct horse"),
+					testAccCheckStateDoesNotContain("consulclient_keys.app", "correct horse"),
+				),
+			},
+			{
+				// Values that look like the stored hashes are still
+				// hashed when sensitive, and kept as they are otherwise.
+				Config: testAccProviderConfig(consul) + fmt.Sprintf(testAccConsulKeysConfig_sensitive, "sha256:hunter2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulKeysValue(consul, "test/secret", "sha256:hunter2"),
+					testAccCheckConsulKeysValue(consul, "test/digest", "sha256:0123"),
+					testAccCheckStateDoesNotContain("consulclient_keys.app", "sha256:hunter2"),
+					testAccCheckStateContains("consulclient_keys.app", sensitiveValueHash("sha256:hunter2")),
+					testAccCheckStateContains("consulclient_keys.app", "sha256:0123"),
+				),
+			},
+		},
+	})
+}
+
+func TestKeyClient_redactsValues(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	client, err := (&ProviderConfig{Host: consul.Address()}).NewClient()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	keyClient := newKeyClient(client.KV(), "dc1", "")
+
+	if err := keyClient.Put("test/secret", "hunter2", 0); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	txn := keyClient.NewTxn()
+	txn.Set("test/secret", "hunter3", 0)
+	if err := txn.Commit(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := keyClient.Get("test/secret"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "hunter") {
+		t.Fatalf("values were logged: %s", buf.String())
+	}
+}
+
+func TestKeyClient_cas(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	client, err := (&ProviderConfig{Host: consul.Address()}).NewClient()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	keyClient := newKeyClient(client.KV(), "dc1", "")
+	keyClient.cas = true
+
+	if err := keyClient.Put("test/set", "one", 0); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := keyClient.Get("test/set"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := keyClient.Put("test/set", "two", 0); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The index read before the last write is now stale.
+	err = keyClient.Put("test/set", "three", 0)
+	if err == nil || !strings.Contains(err.Error(), "modified since it was last read") {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	err = keyClient.Delete("test/set")
+	if err == nil || !strings.Contains(err.Error(), "modified since it was last read") {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+
+	if _, err := keyClient.Get("test/set"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := keyClient.Delete("test/set"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := testAccCheckConsulKeysRemoved(consul, "test/set")(nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyClient_txn(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	client, err := (&ProviderConfig{Host: consul.Address()}).NewClient()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	keyClient := newKeyClient(client.KV(), "dc1", "")
+	keyClient.cas = true
+
+	txn := keyClient.NewTxn()
+	for i := 0; i < maxTxnOps+6; i++ {
+		txn.Set(fmt.Sprintf("test/%02d", i), "one", 0)
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if consul.txns != 2 {
+		t.Fatalf("expected the writes to be split in 2 transactions, got %d", consul.txns)
+	}
+
+	// The indexes returned by the transactions allow further writes.
+	txn = keyClient.NewTxn()
+	txn.Set("test/00", "two", 0)
+	txn.Delete("test/01")
+	if err := txn.Commit(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// A stale index rolls back the whole transaction.
+	consul.lock.Lock()
+	consul.kv["test/02"].ModifyIndex++
+	consul.lock.Unlock()
+
+	txn = keyClient.NewTxn()
+	txn.Set("test/00", "three", 0)
+	txn.Check("test/02")
+	err = txn.Commit()
+	if err == nil || !strings.Contains(err.Error(), "Consul key 'test/02': it was modified since it was last read") {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if err := testAccCheckConsulKeysValue(consul, "test/00", "two")(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := testAccCheckConsulKeysRemoved(consul, "test/01")(nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testAccCheckConsulKeyLockReleased(consul *fakeConsul, key string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		pair, ok := consul.kv[key]
+		if !ok {
+			return fmt.Errorf("Lock '%s' was never acquired", key)
+		}
+		if pair.Flags != consulapi.LockFlagValue {
+			return fmt.Errorf("Key '%s' has flags %d; want the ones of a lock", key, pair.Flags)
+		}
+		if pair.Session != "" {
+			return fmt.Errorf("Lock '%s' is still held by session '%s'", key, pair.Session)
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulKeysValue(consul *fakeConsul, path, value string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		pair, ok := consul.kv[path]
+		if !ok {
+			return fmt.Errorf("Key '%s' does not exist", path)
+		}
+		if string(pair.Value) != value {
+			return fmt.Errorf("Key '%s' has value '%s'; want '%s'", path, pair.Value, value)
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulKeysFlags(consul *fakeConsul, path string, flags uint64) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		pair, ok := consul.kv[path]
+		if !ok {
+			return fmt.Errorf("Key '%s' does not exist", path)
+		}
+		if pair.Flags != flags {
+			return fmt.Errorf("Key '%s' has flags %d; want %d", path, pair.Flags, flags)
+		}
+		return nil
+	}
+}
+
+// testAccCheckStateDoesNotContain checks that none of the attributes of the
+// resource n holds any of the values.
+func testAccCheckStateDoesNotContain(n string, values ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+		for k, v := range rs.Primary.Attributes {
+			for _, value := range values {
+				if strings.Contains(v, value) {
+					return fmt.Errorf("Attribute '%s' of %s holds '%s'", k, n, value)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// testAccCheckStateContains checks that one of the attributes of the
+// resource n holds the value.
+func testAccCheckStateContains(n, value string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+		for _, v := range rs.Primary.Attributes {
+			if v == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("No attribute of %s holds '%s': %v", n, value, rs.Primary.Attributes)
+	}
+}
+
+func testAccCheckConsulKeysRemoved(consul *fakeConsul, paths ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		for _, path := range paths {
+			if _, ok := consul.kv[path]; ok {
+				return fmt.Errorf("Key '%s' still exists", path)
+			}
+		}
+		return nil
+	}
+}
+
+const testAccConsulKeysConfig = `
+resource "consulclient_keys" "app" {
+	key {
+		path   = "test/set"
+		value  = "acceptance"
+		delete = true
+	}
+
+	key {
+		path   = "test/other"
+		value  = "value"
+		delete = true
+	}
+}
+`
+
+const testAccConsulKeysConfig_update = `
+resource "consulclient_keys" "app" {
+	key {
+		path   = "test/set"
+		value  = "acceptanceUpdated"
+		delete = true
+	}
+}
+`
+
+const testAccConsulKeysConfig_import = `
+resource "consulclient_keys" "app" {
+	key {
+		path  = "test/set"
+		value = "acceptance"
+	}
+
+	key {
+		path  = "test/other"
+		value = "value"
+	}
+}
+`
+
+const testAccConsulKeysConfig_multipleEndpoints = `
+resource "consulclient_keys" "primary" {
+	key {
+		path   = "test/primary"
+		value  = "one"
+		delete = true
+	}
+}
+
+resource "consulclient_keys" "secondary" {
+	host = "%s"
+
+	key {
+		path   = "test/secondary"
+		value  = "two"
+		delete = true
+	}
+}
+`
+
+const testAccConsulKeysConfig_lock = `
+resource "consulclient_keys" "app" {
+	cas = true
+
+	lock {
+		key       = "locks/app"
+		wait_time = "100ms"
+	}
+
+	key {
+		path   = "test/set"
+		value  = "%s"
+		delete = true
+	}
+}
+`
+
+const testAccConsulKeysConfig_flags = `
+resource "consulclient_keys" "app" {
+	key {
+		path   = "test/flagged"
+		value  = "value"
+		flags  = %d
+		delete = true
+	}
+}
+`
+
+const testAccConsulKeysConfig_sensitive = `
+resource "consulclient_keys" "app" {
+	key {
+		path            = "test/secret"
+		sensitive_value = "%s"
+		delete          = true
+	}
+
+	key {
+		path                   = "test/secret_binary"
+		sensitive_value_base64 = "AAH/"
+		delete                 = true
+	}
+
+	key {
+		path   = "test/digest"
+		value  = "sha256:0123"
+		delete = true
+	}
+}
+`
+
+const testAccConsulKeysConfig_sensitivePlain = `
+resource "consulclient_keys" "app" {
+	key {
+		path      = "test/secret"
+		value     = "hunter2"
+		sensitive = true
+		delete    = true
+	}
+}
+`
+
+const testAccConsulKeysConfig_base64 = `
+resource "consulclient_keys" "app" {
+	key {
+		path         = "test/binary"
+		value_base64 = "%s"
+		delete       = true
+	}
+}
+`
@@ -5,6 +5,7 @@ package provider
 
 import (
 	"fmt"
+	"log"
 
 	consulapi "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/terraform/helper/schema"
@@ -17,6 +18,10 @@ func resourceConsulNode() *schema.Resource {
 		Read:   resourceConsulNodeRead,
 		Delete: resourceConsulNodeDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulNodeImport,
+		},
+
 		Schema: map[string]*schema.Schema{
 			"host": {
 				Type:     schema.TypeString,
@@ -69,10 +74,29 @@ func resourceConsulNode() *schema.Resource {
 				ForceNew: true,
 			},
 
-			"token": {
-				Type:     schema.TypeString,
+			"node_meta": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"tagged_addresses": {
+				Type:     schema.TypeMap,
 				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
+
+			"check": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: catalogHealthCheckSchema(),
+				},
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
 		},
 	}
 }
@@ -94,15 +118,12 @@ func resourceConsulNodeCreate(d *schema.ResourceData, meta interface{}) error {
 		dc = v.(string)
 	} else {
 		var err error
-		if dc, err = getDC(d, client); err != nil {
+		if dc, err = resolvedConfig.getDC(d, client); err != nil {
 			return err
 		}
 	}
 
-	var token string
-	if v, ok := d.GetOk("token"); ok {
-		token = v.(string)
-	}
+	token := resolvedConfig.Token
 
 	// Setup the operations using the datacenter
 	wOpts := consulapi.WriteOptions{Datacenter: dc, Token: token}
@@ -110,10 +131,15 @@ func resourceConsulNodeCreate(d *schema.ResourceData, meta interface{}) error {
 	address := d.Get("address").(string)
 	name := d.Get("name").(string)
 
+	checks := expandCatalogHealthChecks(d, name)
+
 	registration := &consulapi.CatalogRegistration{
-		Address:    address,
-		Datacenter: dc,
-		Node:       name,
+		Address:         address,
+		Datacenter:      dc,
+		Node:            name,
+		NodeMeta:        expandStringMap(d.Get("node_meta")),
+		TaggedAddresses: expandStringMap(d.Get("tagged_addresses")),
+		Checks:          checks,
 	}
 
 	if _, err := catalog.Register(registration, &wOpts); err != nil {
@@ -121,6 +147,10 @@ func resourceConsulNodeCreate(d *schema.ResourceData, meta interface{}) error {
 			name, address, dc, err)
 	}
 
+	if err := deregisterRemovedCatalogHealthChecks(catalog, d, name, checks, &wOpts); err != nil {
+		return err
+	}
+
 	// Update the resource
 	qOpts := consulapi.QueryOptions{Datacenter: dc}
 	if _, _, err := catalog.Node(name, &qOpts); err != nil {
@@ -132,7 +162,7 @@ func resourceConsulNodeCreate(d *schema.ResourceData, meta interface{}) error {
 
 	d.SetId(fmt.Sprintf("%s-%s", name, address))
 
-	return nil
+	return resourceConsulNodeRead(d, meta)
 }
 
 func resourceConsulNodeRead(d *schema.ResourceData, meta interface{}) error {
@@ -147,10 +177,9 @@ func resourceConsulNodeRead(d *schema.ResourceData, meta interface{}) error {
 	}
 	catalog := client.Catalog()
 
-	// Get the DC, error if not available.
-	var dc string
-	if v, ok := d.GetOk("datacenter"); ok {
-		dc = v.(string)
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
 	}
 
 	name := d.Get("name").(string)
@@ -158,10 +187,35 @@ func resourceConsulNodeRead(d *schema.ResourceData, meta interface{}) error {
 	// Setup the operations using the datacenter
 	qOpts := consulapi.QueryOptions{Datacenter: dc}
 
-	if _, _, err := catalog.Node(name, &qOpts); err != nil {
+	node, _, err := catalog.Node(name, &qOpts)
+	if err != nil {
 		return fmt.Errorf("Failed to get name '%s' from Consul catalog: %v", name, err)
 	}
 
+	if node == nil || node.Node == nil {
+		log.Printf("[WARN] Consul catalog node '%s' not found in %s, removing from state", name, dc)
+		d.SetId("")
+		return nil
+	}
+
+	checks, err := readCatalogHealthChecks(client.Health(), d, name, &qOpts)
+	if err != nil {
+		return err
+	}
+
+	d.Set("address", node.Node.Address)
+	d.Set("name", node.Node.Node)
+	d.Set("datacenter", dc)
+	if err := d.Set("node_meta", node.Node.Meta); err != nil {
+		return fmt.Errorf("Failed to store node_meta of Consul catalog node '%s': %v", name, err)
+	}
+	if err := d.Set("tagged_addresses", node.Node.TaggedAddresses); err != nil {
+		return fmt.Errorf("Failed to store tagged_addresses of Consul catalog node '%s': %v", name, err)
+	}
+	if err := d.Set("check", checks); err != nil {
+		return fmt.Errorf("Failed to store checks of Consul catalog node '%s': %v", name, err)
+	}
+
 	return nil
 }
 
@@ -182,15 +236,12 @@ func resourceConsulNodeDelete(d *schema.ResourceData, meta interface{}) error {
 		dc = v.(string)
 	} else {
 		var err error
-		if dc, err = getDC(d, client); err != nil {
+		if dc, err = resolvedConfig.getDC(d, client); err != nil {
 			return err
 		}
 	}
 
-	var token string
-	if v, ok := d.GetOk("token"); ok {
-		token = v.(string)
-	}
+	token := resolvedConfig.Token
 
 	// Setup the operations using the datacenter
 	wOpts := consulapi.WriteOptions{Datacenter: dc, Token: token}
@@ -213,3 +264,43 @@ func resourceConsulNodeDelete(d *schema.ResourceData, meta interface{}) error {
 	d.SetId("")
 	return nil
 }
+
+// resourceConsulNodeImport imports the node whose name is given as ID.
+func resourceConsulNodeImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := parseImportTarget(d); err != nil {
+		return nil, err
+	}
+
+	name := d.Id()
+	d.Set("name", name)
+
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return nil, err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return nil, err
+	}
+
+	qOpts := consulapi.QueryOptions{Datacenter: dc}
+	if err := importCatalogHealthChecks(client.Health(), d, name, &qOpts); err != nil {
+		return nil, err
+	}
+
+	if err := resourceConsulNodeRead(d, meta); err != nil {
+		return nil, err
+	}
+
+	if d.Id() == "" {
+		return nil, fmt.Errorf("Consul catalog node '%s' does not exist", name)
+	}
+	d.SetId(fmt.Sprintf("%s-%s", name, d.Get("address").(string)))
+
+	return []*schema.ResourceData{d}, nil
+}
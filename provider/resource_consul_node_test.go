@@ -0,0 +1,301 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulNode_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulNodeDestroy(consul, "foo"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulNodeConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulNodeExists(consul, "foo", "127.0.0.10"),
+					resource.TestCheckResourceAttr("consulclient_node.foo", "id", "foo-127.0.0.10"),
+					resource.TestCheckResourceAttr("consulclient_node.foo", "datacenter", "dc1"),
+				),
+			},
+			{
+				ResourceName:      "consulclient_node.foo",
+				ImportState:       true,
+				ImportStateId:     "foo",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulNode_removedOutOfBand(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulNodeDestroy(consul, "foo"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulNodeConfig,
+				Check:  testAccCheckConsulNodeExists(consul, "foo", "127.0.0.10"),
+			},
+			{
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					delete(consul.nodes, "foo")
+				},
+				Config:             testAccProviderConfig(consul) + testAccConsulNodeConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulNodeConfig,
+				Check:  testAccCheckConsulNodeExists(consul, "foo", "127.0.0.10"),
+			},
+		},
+	})
+}
+
+func TestAccConsulNode_meta(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulNodeDestroy(consul, "foo"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulNodeConfig_meta,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulclient_node.foo", "node_meta.%", "2"),
+					resource.TestCheckResourceAttr("consulclient_node.foo", "node_meta.rack", "r1"),
+					resource.TestCheckResourceAttr("consulclient_node.foo", "tagged_addresses.%", "1"),
+					resource.TestCheckResourceAttr("consulclient_node.foo", "tagged_addresses.wan", "203.0.113.10"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulNodeConfig_metaUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulclient_node.foo", "id", "foo-127.0.0.10"),
+					resource.TestCheckResourceAttr("consulclient_node.foo", "node_meta.%", "1"),
+					resource.TestCheckResourceAttr("consulclient_node.foo", "node_meta.rack", "r2"),
+					resource.TestCheckResourceAttr("consulclient_node.foo", "tagged_addresses.%", "0"),
+				),
+			},
+			{
+				// Metadata changed out of band is detected.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.nodes["foo"].node.Meta["rack"] = "r3"
+				},
+				Config:             testAccProviderConfig(consul) + testAccConsulNodeConfig_metaUpdate,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulNode_checks(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulNodeDestroy(consul, "db"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulNodeConfig_checks,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulCatalogCheck(consul, "db", "db-tcp", "", "passing"),
+					testAccCheckConsulCatalogCheck(consul, "db", "db-http", "", "critical"),
+					resource.TestCheckResourceAttr("consulclient_node.db", "check.#", "2"),
+					resource.TestCheckResourceAttr("consulclient_node.db", "check.0.check_id", "db-tcp"),
+					resource.TestCheckResourceAttr("consulclient_node.db", "check.0.interval", "10s"),
+					resource.TestCheckResourceAttr("consulclient_node.db", "check.1.check_id", "db-http"),
+					resource.TestCheckResourceAttr("consulclient_node.db", "check.1.status", "critical"),
+					resource.TestCheckResourceAttr("consulclient_node.db", "check.1.header.#", "1"),
+				),
+			},
+			{
+				// A status changed out of band is detected.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.nodes["db"].checks["db-tcp"].Status = consulapi.HealthCritical
+				},
+				Config:             testAccProviderConfig(consul) + testAccConsulNodeConfig_checks,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulNodeConfig_checksUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulCatalogCheck(consul, "db", "db-tcp", "", "passing"),
+					testAccCheckConsulCatalogCheckRemoved(consul, "db", "db-http"),
+					resource.TestCheckResourceAttr("consulclient_node.db", "check.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "consulclient_node.db",
+				ImportState:       true,
+				ImportStateId:     "db",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckConsulCatalogCheck(consul *fakeConsul, node, id, serviceID, status string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		n, ok := consul.nodes[node]
+		if !ok {
+			return fmt.Errorf("Node '%s' does not exist", node)
+		}
+		check, ok := n.checks[id]
+		if !ok {
+			return fmt.Errorf("Check '%s' is not registered on node '%s'", id, node)
+		}
+		if check.ServiceID != serviceID {
+			return fmt.Errorf("Check '%s' belongs to service '%s'; want '%s'", id, check.ServiceID, serviceID)
+		}
+		if check.Status != status {
+			return fmt.Errorf("Check '%s' has status '%s'; want '%s'", id, check.Status, status)
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulCatalogCheckRemoved(consul *fakeConsul, node, id string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		if n, ok := consul.nodes[node]; ok {
+			if _, ok := n.checks[id]; ok {
+				return fmt.Errorf("Check '%s' is still registered on node '%s'", id, node)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulNodeExists(consul *fakeConsul, name, address string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		n, ok := consul.nodes[name]
+		if !ok {
+			return fmt.Errorf("Node '%s' does not exist", name)
+		}
+		if n.node.Address != address {
+			return fmt.Errorf("Node '%s' has address '%s'; want '%s'", name, n.node.Address, address)
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulNodeDestroy(consul *fakeConsul, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		if _, ok := consul.nodes[name]; ok {
+			return fmt.Errorf("Node '%s' still exists", name)
+		}
+		return nil
+	}
+}
+
+const testAccConsulNodeConfig = `
+resource "consulclient_node" "foo" {
+	name    = "foo"
+	address = "127.0.0.10"
+}
+`
+
+const testAccConsulNodeConfig_checks = `
+resource "consulclient_node" "db" {
+	name    = "db"
+	address = "10.0.0.5"
+
+	check {
+		check_id = "db-tcp"
+		name     = "Database port"
+		status   = "passing"
+		tcp      = "10.0.0.5:5432"
+		interval = "10s"
+	}
+
+	check {
+		check_id = "db-http"
+		name     = "Database admin"
+		http     = "https://10.0.0.5/health"
+		interval = "30s"
+		timeout  = "5s"
+
+		header {
+			name  = "Authorization"
+			value = ["Bearer monitoring"]
+		}
+	}
+}
+`
+
+const testAccConsulNodeConfig_checksUpdate = `
+resource "consulclient_node" "db" {
+	name    = "db"
+	address = "10.0.0.5"
+
+	check {
+		check_id = "db-tcp"
+		name     = "Database port"
+		status   = "passing"
+		tcp      = "10.0.0.5:5432"
+		interval = "10s"
+	}
+}
+`
+
+const testAccConsulNodeConfig_meta = `
+resource "consulclient_node" "foo" {
+	name    = "foo"
+	address = "127.0.0.10"
+
+	node_meta {
+		rack = "r1"
+		env  = "test"
+	}
+
+	tagged_addresses {
+		wan = "203.0.113.10"
+	}
+}
+`
+
+const testAccConsulNodeConfig_metaUpdate = `
+resource "consulclient_node" "foo" {
+	name    = "foo"
+	address = "127.0.0.10"
+
+	node_meta {
+		rack = "r2"
+	}
+}
+`
@@ -17,6 +17,10 @@ func resourceConsulPreparedQuery() *schema.Resource {
 		Read:   resourceConsulPreparedQueryRead,
 		Delete: resourceConsulPreparedQueryDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulImportState,
+		},
+
 		SchemaVersion: 0,
 
 		Schema: map[string]*schema.Schema{
@@ -72,10 +76,9 @@ func resourceConsulPreparedQuery() *schema.Resource {
 				Optional: true,
 			},
 
-			"token": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
 
 			"stored_token": {
 				Type:     schema.TypeString,
@@ -169,7 +172,7 @@ func resourceConsulPreparedQueryCreate(d *schema.ResourceData, meta interface{})
 	}
 	wo := &consulapi.WriteOptions{
 		Datacenter: d.Get("datacenter").(string),
-		Token:      d.Get("token").(string),
+		Token:      resolvedConfig.Token,
 	}
 
 	pq := preparedQueryDefinitionFromResourceData(d)
@@ -195,7 +198,7 @@ func resourceConsulPreparedQueryUpdate(d *schema.ResourceData, meta interface{})
 	}
 	wo := &consulapi.WriteOptions{
 		Datacenter: d.Get("datacenter").(string),
-		Token:      d.Get("token").(string),
+		Token:      resolvedConfig.Token,
 	}
 
 	pq := preparedQueryDefinitionFromResourceData(d)
@@ -219,7 +222,7 @@ func resourceConsulPreparedQueryRead(d *schema.ResourceData, meta interface{}) e
 	}
 	qo := &consulapi.QueryOptions{
 		Datacenter: d.Get("datacenter").(string),
-		Token:      d.Get("token").(string),
+		Token:      resolvedConfig.Token,
 	}
 
 	queries, _, err := client.PreparedQuery().Get(d.Id(), qo)
@@ -246,20 +249,37 @@ func resourceConsulPreparedQueryRead(d *schema.ResourceData, meta interface{}) e
 	d.Set("only_passing", pq.Service.OnlyPassing)
 	d.Set("tags", pq.Service.Tags)
 
-	if pq.Service.Failover.NearestN > 0 {
-		d.Set("failover.0.nearest_n", pq.Service.Failover.NearestN)
+	// Nested blocks can only be set as a whole.
+	var failover []interface{}
+	if pq.Service.Failover.NearestN > 0 || len(pq.Service.Failover.Datacenters) > 0 {
+		failover = append(failover, map[string]interface{}{
+			"nearest_n":   pq.Service.Failover.NearestN,
+			"datacenters": pq.Service.Failover.Datacenters,
+		})
 	}
-	if len(pq.Service.Failover.Datacenters) > 0 {
-		d.Set("failover.0.datacenters", pq.Service.Failover.Datacenters)
+	if err := d.Set("failover", failover); err != nil {
+		return err
 	}
 
+	var dns []interface{}
 	if pq.DNS.TTL != "" {
-		d.Set("dns.0.ttl", pq.DNS.TTL)
+		dns = append(dns, map[string]interface{}{
+			"ttl": pq.DNS.TTL,
+		})
+	}
+	if err := d.Set("dns", dns); err != nil {
+		return err
 	}
 
+	var template []interface{}
 	if pq.Template.Type != "" {
-		d.Set("template.0.type", pq.Template.Type)
-		d.Set("template.0.regexp", pq.Template.Regexp)
+		template = append(template, map[string]interface{}{
+			"type":   pq.Template.Type,
+			"regexp": pq.Template.Regexp,
+		})
+	}
+	if err := d.Set("template", template); err != nil {
+		return err
 	}
 
 	return nil
@@ -277,7 +297,7 @@ func resourceConsulPreparedQueryDelete(d *schema.ResourceData, meta interface{})
 	}
 	writeOpts := &consulapi.WriteOptions{
 		Datacenter: d.Get("datacenter").(string),
-		Token:      d.Get("token").(string),
+		Token:      resolvedConfig.Token,
 	}
 
 	if _, err := client.PreparedQuery().Delete(d.Id(), writeOpts); err != nil {
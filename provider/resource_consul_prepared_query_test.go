@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulPreparedQuery_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulPreparedQueryDestroy(consul),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulPreparedQueryConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulPreparedQueryExists(consul, "consulclient_prepared_query.foo", "redis"),
+					resource.TestCheckResourceAttr("consulclient_prepared_query.foo", "name", "foo"),
+					resource.TestCheckResourceAttr("consulclient_prepared_query.foo", "tags.#", "1"),
+					resource.TestCheckResourceAttr("consulclient_prepared_query.foo", "only_passing", "true"),
+					resource.TestCheckResourceAttr("consulclient_prepared_query.foo", "failover.0.nearest_n", "3"),
+					resource.TestCheckResourceAttr("consulclient_prepared_query.foo", "failover.0.datacenters.#", "2"),
+					resource.TestCheckResourceAttr("consulclient_prepared_query.foo", "dns.0.ttl", "8m"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulPreparedQueryConfig_update,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulPreparedQueryExists(consul, "consulclient_prepared_query.foo", "memcached"),
+					resource.TestCheckResourceAttr("consulclient_prepared_query.foo", "only_passing", "false"),
+				),
+			},
+			{
+				ResourceName:      "consulclient_prepared_query.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckConsulPreparedQueryExists(consul *fakeConsul, name, service string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		query, ok := consul.queries[rs.Primary.ID]
+		if !ok {
+			return fmt.Errorf("Prepared query '%s' does not exist", rs.Primary.ID)
+		}
+		if query.Service.Service != service {
+			return fmt.Errorf("Prepared query '%s' targets service '%s'; want '%s'", rs.Primary.ID, query.Service.Service, service)
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulPreparedQueryDestroy(consul *fakeConsul) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		if len(consul.queries) != 0 {
+			return fmt.Errorf("%d prepared queries still exist", len(consul.queries))
+		}
+		return nil
+	}
+}
+
+const testAccConsulPreparedQueryConfig = `
+resource "consulclient_prepared_query" "foo" {
+	name         = "foo"
+	service      = "redis"
+	tags         = ["prod"]
+	near         = "_agent"
+	only_passing = true
+
+	failover {
+		nearest_n   = 3
+		datacenters = ["dc2", "dc3"]
+	}
+
+	dns {
+		ttl = "8m"
+	}
+}
+`
+
+const testAccConsulPreparedQueryConfig_update = `
+resource "consulclient_prepared_query" "foo" {
+	name    = "foo"
+	service = "memcached"
+	tags    = ["prod"]
+	near    = "_agent"
+
+	failover {
+		nearest_n   = 3
+		datacenters = ["dc2", "dc3"]
+	}
+
+	dns {
+		ttl = "8m"
+	}
+}
+`
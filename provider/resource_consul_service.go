@@ -17,6 +17,10 @@ func resourceConsulService() *schema.Resource {
 		Read:   resourceConsulServiceRead,
 		Delete: resourceConsulServiceDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulImportState,
+		},
+
 		Schema: map[string]*schema.Schema{
 			"host": {
 				Type:     schema.TypeString,
@@ -50,10 +54,9 @@ func resourceConsulService() *schema.Resource {
 				Optional: true,
 			},
 
-			"token": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
 
 			"address": {
 				Type:     schema.TypeString,
@@ -86,6 +89,25 @@ func resourceConsulService() *schema.Resource {
 				Elem:     &schema.Schema{Type: schema.TypeString},
 				ForceNew: true,
 			},
+
+			"meta": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"enable_tag_override": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+
+			"check": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: healthCheckSchema(),
+				},
+			},
 		},
 	}
 }
@@ -128,10 +150,23 @@ func resourceConsulServiceCreate(d *schema.ResourceData, meta interface{}) error
 		registration.Tags = s
 	}
 
+	registration.Meta = expandStringMap(d.Get("meta"))
+	registration.EnableTagOverride = d.Get("enable_tag_override").(bool)
+
+	checks, err := expandServiceChecks(d, identifier)
+	if err != nil {
+		return err
+	}
+	registration.Checks = checks
+
 	if err := agent.ServiceRegister(&registration); err != nil {
 		return fmt.Errorf("Failed to register service '%s' with Consul agent: %v", name, err)
 	}
 
+	if err := deregisterRemovedServiceChecks(agent, d, identifier, checks); err != nil {
+		return err
+	}
+
 	// Update the resource
 	if serviceMap, err := agent.Services(); err != nil {
 		return fmt.Errorf("Failed to read services from Consul agent: %v", err)
@@ -149,6 +184,18 @@ func resourceConsulServiceCreate(d *schema.ResourceData, meta interface{}) error
 			tags = append(tags, tag)
 		}
 		d.Set("tags", tags)
+		if err := d.Set("meta", service.Meta); err != nil {
+			return fmt.Errorf("Failed to store meta of service '%s': %v", service.ID, err)
+		}
+		d.Set("enable_tag_override", service.EnableTagOverride)
+
+		checks, err := readServiceChecks(agent, d, service.ID)
+		if err != nil {
+			return err
+		}
+		if err := d.Set("check", checks); err != nil {
+			return fmt.Errorf("Failed to store checks of service '%s': %v", service.ID, err)
+		}
 	}
 
 	return nil
@@ -166,17 +213,12 @@ func resourceConsulServiceRead(d *schema.ResourceData, meta interface{}) error {
 	}
 	agent := client.Agent()
 
-	name := d.Get("name").(string)
-	identifier := name
-
-	if serviceId, ok := d.GetOk("service_id"); ok {
-		identifier = serviceId.(string)
-	}
+	identifier := d.Id()
 
 	if services, err := agent.Services(); err != nil {
 		return fmt.Errorf("Failed to get services from Consul agent: %v", err)
 	} else if service, ok := services[identifier]; !ok {
-		return fmt.Errorf("Failed to get service '%s' from Consul agent", identifier)
+		d.SetId("")
 	} else {
 		d.SetId(service.ID)
 
@@ -189,6 +231,18 @@ func resourceConsulServiceRead(d *schema.ResourceData, meta interface{}) error {
 			tags = append(tags, tag)
 		}
 		d.Set("tags", tags)
+		if err := d.Set("meta", service.Meta); err != nil {
+			return fmt.Errorf("Failed to store meta of service '%s': %v", service.ID, err)
+		}
+		d.Set("enable_tag_override", service.EnableTagOverride)
+
+		checks, err := readServiceChecks(agent, d, service.ID)
+		if err != nil {
+			return err
+		}
+		if err := d.Set("check", checks); err != nil {
+			return fmt.Errorf("Failed to store checks of service '%s': %v", service.ID, err)
+		}
 	}
 
 	return nil
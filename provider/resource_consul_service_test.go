@@ -0,0 +1,268 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulService_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulAgentServiceDestroy(consul, "google1"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulServiceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulAgentServiceExists(consul, "google1", "google"),
+					resource.TestCheckResourceAttr("consulclient_service.google", "id", "google1"),
+					resource.TestCheckResourceAttr("consulclient_service.google", "address", "www.google.com"),
+					resource.TestCheckResourceAttr("consulclient_service.google", "service_id", "google1"),
+					resource.TestCheckResourceAttr("consulclient_service.google", "name", "google"),
+					resource.TestCheckResourceAttr("consulclient_service.google", "port", "80"),
+					resource.TestCheckResourceAttr("consulclient_service.google", "tags.#", "2"),
+					resource.TestCheckResourceAttr("consulclient_service.google", "tags.0", "tag0"),
+					resource.TestCheckResourceAttr("consulclient_service.google", "tags.1", "tag1"),
+					resource.TestCheckResourceAttr("consulclient_service.google", "meta.%", "1"),
+					resource.TestCheckResourceAttr("consulclient_service.google", "meta.website", "google"),
+					resource.TestCheckResourceAttr("consulclient_service.google", "enable_tag_override", "true"),
+				),
+			},
+			{
+				ResourceName:      "consulclient_service.google",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccConsulService_checks(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulAgentCheckDestroy(consul, "service:example:1", "service:example:2", "service:example:3"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulServiceConfig_checks,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulAgentCheckExists(consul, "service:example:1", "example", "http"),
+					testAccCheckConsulAgentCheckExists(consul, "service:example:2", "example", "tcp"),
+					testAccCheckConsulAgentCheckExists(consul, "service:example:3", "example", "ttl"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.#", "3"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.0.check_id", "service:example:1"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.0.http", "https://www.hashicorptest.com"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.0.method", "PUT"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.0.interval", "1m0s"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.0.header.#", "2"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.1.tcp", "127.0.0.1:8080"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.1.deregister_critical_service_after", "30s"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.2.ttl", "10s"),
+				),
+			},
+			{
+				// A check changed out of band is detected.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					consul.checks["service:example:2"].Definition.IntervalDuration = time.Minute
+				},
+				Config:             testAccProviderConfig(consul) + testAccConsulServiceConfig_checks,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// Removing a check renumbers the default IDs of the
+				// following ones, which are registered again under their
+				// new ID.
+				Config: testAccProviderConfig(consul) + testAccConsulServiceConfig_checksRemoveMiddle,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulAgentCheckExists(consul, "service:example:1", "example", "http"),
+					testAccCheckConsulAgentCheckExists(consul, "service:example:2", "example", "ttl"),
+					testAccCheckConsulAgentCheckDestroy(consul, "service:example:3"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.#", "2"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.0.check_id", "service:example:1"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.1.check_id", "service:example:2"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.1.ttl", "10s"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.1.tcp", ""),
+				),
+			},
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulServiceConfig_checksUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulAgentCheckExists(consul, "tcp", "example", "tcp"),
+					testAccCheckConsulAgentCheckDestroy(consul, "service:example:1", "service:example:2", "service:example:3"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.#", "1"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.0.check_id", "tcp"),
+					resource.TestCheckResourceAttr("consulclient_service.example", "check.0.timeout", "1s"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckConsulAgentCheckExists(consul *fakeConsul, id, serviceID, checkType string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		check, ok := consul.checks[id]
+		if !ok {
+			return fmt.Errorf("Check '%s' is not registered with the agent", id)
+		}
+		if check.ServiceID != serviceID {
+			return fmt.Errorf("Check '%s' belongs to service '%s'; want '%s'", id, check.ServiceID, serviceID)
+		}
+		if check.Type != checkType {
+			return fmt.Errorf("Check '%s' has type '%s'; want '%s'", id, check.Type, checkType)
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulAgentCheckDestroy(consul *fakeConsul, ids ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		for _, id := range ids {
+			if _, ok := consul.checks[id]; ok {
+				return fmt.Errorf("Check '%s' is still registered with the agent", id)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulAgentServiceExists(consul *fakeConsul, id, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		service, ok := consul.agent[id]
+		if !ok {
+			return fmt.Errorf("Service '%s' is not registered with the agent", id)
+		}
+		if service.Service != name {
+			return fmt.Errorf("Service '%s' has name '%s'; want '%s'", id, service.Service, name)
+		}
+		return nil
+	}
+}
+
+func testAccCheckConsulAgentServiceDestroy(consul *fakeConsul, id string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		if _, ok := consul.agent[id]; ok {
+			return fmt.Errorf("Service '%s' is still registered with the agent", id)
+		}
+		return nil
+	}
+}
+
+const testAccConsulServiceConfig = `
+resource "consulclient_service" "google" {
+	address    = "www.google.com"
+	service_id = "google1"
+	name       = "google"
+	port       = 80
+	tags       = ["tag0", "tag1"]
+
+	meta {
+		website = "google"
+	}
+
+	enable_tag_override = true
+}
+`
+
+const testAccConsulServiceConfig_checks = `
+resource "consulclient_service" "example" {
+	name = "example"
+	port = 80
+
+	check {
+		name     = "HTTP"
+		http     = "https://www.hashicorptest.com"
+		method   = "PUT"
+		interval = "1m"
+
+		header {
+			name  = "foo"
+			value = ["test"]
+		}
+
+		header {
+			name  = "bar"
+			value = ["one", "two"]
+		}
+	}
+
+	check {
+		name                              = "TCP"
+		tcp                               = "127.0.0.1:8080"
+		interval                          = "5s"
+		deregister_critical_service_after = "30s"
+	}
+
+	check {
+		name = "TTL"
+		ttl  = "10s"
+	}
+}
+`
+
+const testAccConsulServiceConfig_checksRemoveMiddle = `
+resource "consulclient_service" "example" {
+	name = "example"
+	port = 80
+
+	check {
+		name     = "HTTP"
+		http     = "https://www.hashicorptest.com"
+		method   = "PUT"
+		interval = "1m"
+
+		header {
+			name  = "foo"
+			value = ["test"]
+		}
+
+		header {
+			name  = "bar"
+			value = ["one", "two"]
+		}
+	}
+
+	check {
+		name = "TTL"
+		ttl  = "10s"
+	}
+}
+`
+
+const testAccConsulServiceConfig_checksUpdate = `
+resource "consulclient_service" "example" {
+	name = "example"
+	port = 80
+
+	check {
+		check_id = "tcp"
+		name     = "TCP"
+		tcp      = "127.0.0.1:8080"
+		interval = "5s"
+		timeout  = "1s"
+	}
+}
+`
@@ -0,0 +1,286 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceConsulSession() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulSessionCreate,
+		Update: resourceConsulSessionUpdate,
+		Read:   resourceConsulSessionRead,
+		Delete: resourceConsulSessionDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulImportState,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"host": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"scheme": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"http_auth": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"ca_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"cert_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"key_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"datacenter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"token":         schemaToken,
+			"token_file":    schemaTokenFile,
+			"token_command": schemaTokenCommand,
+
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			// The node defaults to the one of the agent.
+			"node": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			// The checks default to the serfHealth check of the node.
+			"checks": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"lock_delay": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				Default:          "15s",
+				DiffSuppressFunc: suppressEquivalentDurations,
+				ValidateFunc: makeValidationFunc("lock_delay", []interface{}{
+					validateDurationMin("0ns"),
+				}),
+			},
+
+			"behavior": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  consulapi.SessionBehaviorRelease,
+				ValidateFunc: makeValidationFunc("behavior", []interface{}{
+					validateRegexp(`^(release|delete)$`),
+				}),
+			},
+
+			"ttl": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressEquivalentDurations,
+				ValidateFunc: makeValidationFunc("ttl", []interface{}{
+					validateDurationMin("10s"),
+				}),
+			},
+
+			// renew keeps the session alive while the provider runs, so
+			// that a session with a short TTL lasts until the resources of
+			// the apply that use it are created. Renewal stops when the
+			// provider process exits.
+			"renew": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+		},
+	}
+}
+
+func resourceConsulSessionCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+	wOpts := &consulapi.WriteOptions{
+		Datacenter: dc,
+		Token:      resolvedConfig.Token,
+	}
+
+	lockDelay, err := time.ParseDuration(d.Get("lock_delay").(string))
+	if err != nil {
+		return fmt.Errorf("Failed to parse lock_delay of session: %v", err)
+	}
+
+	entry := &consulapi.SessionEntry{
+		Name:      d.Get("name").(string),
+		Node:      d.Get("node").(string),
+		LockDelay: lockDelay,
+		Behavior:  d.Get("behavior").(string),
+		TTL:       d.Get("ttl").(string),
+	}
+	for _, check := range d.Get("checks").([]interface{}) {
+		entry.Checks = append(entry.Checks, check.(string))
+	}
+
+	id, _, err := client.Session().Create(entry, wOpts)
+	if err != nil {
+		return fmt.Errorf("Failed to create session '%s' in %s: %v", entry.Name, dc, err)
+	}
+
+	d.SetId(id)
+	d.Set("datacenter", dc)
+
+	if d.Get("renew").(bool) && entry.TTL != "" {
+		resolvedConfig.sessions.Start(client.Session(), id, entry.TTL, wOpts)
+	}
+
+	return resourceConsulSessionRead(d, meta)
+}
+
+func resourceConsulSessionUpdate(d *schema.ResourceData, meta interface{}) error {
+	// Only renew can change in place. Sessions that are no longer renewed
+	// are left alone until the provider exits, as stopping their renewal
+	// would destroy them.
+	if ttl := d.Get("ttl").(string); d.Get("renew").(bool) && ttl != "" {
+		config := meta.(*ProviderConfig)
+		resolvedConfig, _, err := config.GetResolvedConfig(d)
+		if err != nil {
+			return err
+		}
+		client, err := resolvedConfig.NewClient()
+		if err != nil {
+			return err
+		}
+
+		dc, err := resolvedConfig.getDC(d, client)
+		if err != nil {
+			return err
+		}
+		wOpts := &consulapi.WriteOptions{
+			Datacenter: dc,
+			Token:      resolvedConfig.Token,
+		}
+
+		resolvedConfig.sessions.Start(client.Session(), d.Id(), ttl, wOpts)
+	}
+
+	return resourceConsulSessionRead(d, meta)
+}
+
+func resourceConsulSessionRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+	token := resolvedConfig.Token
+
+	id := d.Id()
+
+	entry, _, err := client.Session().Info(id, &consulapi.QueryOptions{Datacenter: dc, Token: token})
+	if err != nil {
+		return fmt.Errorf("Failed to get session '%s' in %s: %v", id, dc, err)
+	}
+	if entry == nil {
+		log.Printf("[WARN] Session '%s' expired or was invalidated, removing from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("datacenter", dc)
+	d.Set("name", entry.Name)
+	d.Set("node", entry.Node)
+	if err := d.Set("checks", entry.Checks); err != nil {
+		return fmt.Errorf("Failed to store checks of session '%s': %v", id, err)
+	}
+	d.Set("lock_delay", entry.LockDelay.String())
+	d.Set("behavior", entry.Behavior)
+	d.Set("ttl", entry.TTL)
+
+	return nil
+}
+
+func resourceConsulSessionDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*ProviderConfig)
+	resolvedConfig, _, err := config.GetResolvedConfig(d)
+	if err != nil {
+		return err
+	}
+	client, err := resolvedConfig.NewClient()
+	if err != nil {
+		return err
+	}
+
+	dc, err := resolvedConfig.getDC(d, client)
+	if err != nil {
+		return err
+	}
+	wOpts := &consulapi.WriteOptions{
+		Datacenter: dc,
+		Token:      resolvedConfig.Token,
+	}
+
+	id := d.Id()
+
+	resolvedConfig.sessions.Stop(id)
+	if _, err := client.Session().Destroy(id, wOpts); err != nil {
+		return fmt.Errorf("Failed to destroy session '%s' in %s: %v", id, dc, err)
+	}
+
+	// Clear the ID
+	d.SetId("")
+	return nil
+}
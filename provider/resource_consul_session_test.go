@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccConsulSession_basic(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+	defer testAccProviderEnv(consul)()
+
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckConsulSessionDestroy(consul),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(consul) + testAccConsulSessionConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckConsulSessionExists(consul, "consulclient_session.lock", &id),
+					resource.TestCheckResourceAttr("consulclient_session.lock", "name", "lock"),
+					resource.TestCheckResourceAttr("consulclient_session.lock", "datacenter", "dc1"),
+					resource.TestCheckResourceAttr("consulclient_session.lock", "node", "agent-dc1"),
+					resource.TestCheckResourceAttr("consulclient_session.lock", "checks.#", "1"),
+					resource.TestCheckResourceAttr("consulclient_session.lock", "checks.0", "serfHealth"),
+					resource.TestCheckResourceAttr("consulclient_session.lock", "lock_delay", "5s"),
+					resource.TestCheckResourceAttr("consulclient_session.lock", "behavior", "delete"),
+					resource.TestCheckResourceAttr("consulclient_session.lock", "ttl", "30s"),
+					resource.TestCheckResourceAttr("consulclient_session.default", "lock_delay", "15s"),
+					resource.TestCheckResourceAttr("consulclient_session.default", "behavior", "release"),
+					resource.TestCheckResourceAttr("consulclient_session.default", "ttl", ""),
+				),
+			},
+			{
+				// A session invalidated out of band is created again.
+				PreConfig: func() {
+					consul.lock.Lock()
+					defer consul.lock.Unlock()
+					delete(consul.sessions, id)
+				},
+				Config: testAccProviderConfig(consul) + testAccConsulSessionConfig,
+				Check: resource.ComposeTestCheckFunc(
+					func(s *terraform.State) error {
+						old := id
+						if err := testAccCheckConsulSessionExists(consul, "consulclient_session.lock", &id)(s); err != nil {
+							return err
+						}
+						if id == old {
+							return fmt.Errorf("Session '%s' was not created again", id)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:            "consulclient_session.lock",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"renew"},
+			},
+		},
+	})
+}
+
+func TestSessionRenewer(t *testing.T) {
+	consul := newFakeConsul("dc1")
+	defer consul.Close()
+
+	client, err := (&ProviderConfig{Host: consul.Address()}).NewClient()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	session := client.Session()
+
+	id, _, err := session.Create(&consulapi.SessionEntry{TTL: "200ms"}, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	r := newSessionRenewer()
+	r.Start(session, id, "200ms", nil)
+	r.Start(session, id, "200ms", nil)
+	time.Sleep(350 * time.Millisecond)
+
+	consul.lock.Lock()
+	renewals := consul.renewals[id]
+	consul.lock.Unlock()
+	if renewals < 2 || renewals > 4 {
+		t.Fatalf("expected the session to be renewed 2 to 4 times, got %d", renewals)
+	}
+
+	r.Stop(id)
+	for i := 0; i < 50; i++ {
+		consul.lock.Lock()
+		_, ok := consul.sessions[id]
+		consul.lock.Unlock()
+		if !ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected session '%s' to be destroyed once no longer renewed", id)
+}
+
+func testAccCheckConsulSessionExists(consul *fakeConsul, name string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Resource '%s' not found", name)
+		}
+
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		if _, ok := consul.sessions[rs.Primary.ID]; !ok {
+			return fmt.Errorf("Session '%s' does not exist", rs.Primary.ID)
+		}
+		*id = rs.Primary.ID
+		return nil
+	}
+}
+
+func testAccCheckConsulSessionDestroy(consul *fakeConsul) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		consul.lock.Lock()
+		defer consul.lock.Unlock()
+
+		for id := range consul.sessions {
+			return fmt.Errorf("Session '%s' still exists", id)
+		}
+		return nil
+	}
+}
+
+const testAccConsulSessionConfig = `
+resource "consulclient_session" "lock" {
+	name       = "lock"
+	lock_delay = "5s"
+	behavior   = "delete"
+	ttl        = "30s"
+	renew      = true
+}
+
+resource "consulclient_session" "default" {
+}
+`
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"log"
+	"sync"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// sessionRenewer is a concurrency-safe registry of the sessions renewed in
+// the background by a provider instance.
+//
+// Sessions with a TTL are invalidated unless they are renewed before it
+// expires, so the ones with renew set are kept alive for as long as the
+// provider runs, that is, for the duration of the plan or apply. Renewal
+// stops when the session is destroyed or found to be invalidated, or when
+// the provider exits.
+type sessionRenewer struct {
+	lock     sync.Mutex
+	sessions map[string]chan struct{}
+}
+
+func newSessionRenewer() *sessionRenewer {
+	return &sessionRenewer{
+		sessions: make(map[string]chan struct{}),
+	}
+}
+
+// Start renews the session id with the given TTL in the background, unless
+// it is already being renewed.
+func (r *sessionRenewer) Start(session *consulapi.Session, id, ttl string, wOpts *consulapi.WriteOptions) {
+	if r == nil {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.sessions[id]; ok {
+		return
+	}
+	doneCh := make(chan struct{})
+	r.sessions[id] = doneCh
+
+	go func() {
+		err := session.RenewPeriodic(ttl, id, wOpts, doneCh)
+		if err != nil {
+			log.Printf("[WARN] Stopped renewing session '%s': %v", id, err)
+		}
+
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		if r.sessions[id] == doneCh {
+			delete(r.sessions, id)
+		}
+	}()
+}
+
+// Stop stops renewing the session id, if it is being renewed, which also
+// destroys it.
+func (r *sessionRenewer) Stop(id string) {
+	if r == nil {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if doneCh, ok := r.sessions[id]; ok {
+		close(doneCh)
+		delete(r.sessions, id)
+	}
+}
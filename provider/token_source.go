@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// The attributes through which resources and data sources override the
+// token of the provider, either directly or through a token file or a
+// token command. At most one of them can be set.
+//
+// A token given directly is stored in the state in plain text, not hashed:
+// refreshing and deleting a resource, and updates that leave the token
+// alone, only have the state to connect to Consul with, and a hash could not
+// authenticate them. Sensitive only hides it from the plan output. Only
+// token_file and token_command keep the token itself out of the state.
+var (
+	schemaToken = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Sensitive:     true,
+		ConflictsWith: []string{"token_file", "token_command"},
+	}
+
+	schemaTokenFile = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		ConflictsWith: []string{"token", "token_command"},
+	}
+
+	schemaTokenCommand = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		ConflictsWith: []string{"token", "token_file"},
+	}
+)
+
+// defaultTokenCommandTTL is how long the token printed by a token command is
+// used before the command is run again.
+const defaultTokenCommandTTL = "5m"
+
+// tokenCommandTimeout is how long a token command can run before it is
+// killed.
+var tokenCommandTimeout = time.Minute
+
+// tokenCache is a concurrency-safe cache of the tokens printed by the token
+// commands run by a provider instance.
+//
+// The tokens read from a token file are not cached: the file is read again
+// each time a client is needed, so that tokens rotated by an agent or a
+// sidecar are picked up during long applies.
+type tokenCache struct {
+	lock   sync.Mutex
+	ttl    time.Duration
+	tokens map[string]*cachedToken
+}
+
+// cachedToken is the token printed by a command. Its lock is held while the
+// command runs, so that the callers needing the same token wait for it to be
+// printed once, without blocking the callers of other commands.
+type cachedToken struct {
+	lock    sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newTokenCache(ttl time.Duration) *tokenCache {
+	return &tokenCache{
+		ttl:    ttl,
+		tokens: make(map[string]*cachedToken),
+	}
+}
+
+// Command returns the token printed by command, running it unless it was
+// run less than the TTL of the cache ago.
+func (c *tokenCache) Command(command string) (string, error) {
+	if c == nil {
+		return runTokenCommand(command)
+	}
+
+	c.lock.Lock()
+	cached, ok := c.tokens[command]
+	if !ok {
+		cached = &cachedToken{}
+		c.tokens[command] = cached
+	}
+	c.lock.Unlock()
+
+	cached.lock.Lock()
+	defer cached.lock.Unlock()
+
+	if time.Now().Before(cached.expires) {
+		return cached.token, nil
+	}
+
+	token, err := runTokenCommand(command)
+	if err != nil {
+		return "", err
+	}
+	cached.token = token
+	cached.expires = time.Now().Add(c.ttl)
+	return token, nil
+}
+
+// readTokenFile returns the token held by the file at path.
+func readTokenFile(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read Consul token file: %v", err)
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("Consul token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// runTokenCommand runs command through the shell and returns the token it
+// prints, killing it if it runs for longer than tokenCommandTimeout. The
+// output of the command is never logged, as it is a secret.
+func runTokenCommand(command string) (string, error) {
+	log.Printf("[DEBUG] Running Consul token command")
+
+	ctx, cancel := context.WithTimeout(context.Background(), tokenCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Killing the shell leaves the processes it started running, which
+	// may keep its output open; they are not waited for either.
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("Consul token command timed out after %s", tokenCommandTimeout)
+		}
+		return "", fmt.Errorf("Failed to run Consul token command: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("Consul token command printed no token")
+	}
+	return token, nil
+}